@@ -0,0 +1,221 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config holds all runtime configuration, loaded from environment variables.
+type Config struct {
+	Port string
+
+	DBHost     string
+	DBPort     string
+	DBUser     string
+	DBPassword string
+	DBName     string
+
+	// Connection pool sizing; idle must not exceed open.
+	DBMaxOpenConns           int
+	DBMaxIdleConns           int
+	DBConnMaxLifetimeMinutes int
+
+	// DBSSLMode is one of disable, require, verify-ca or verify-full.
+	// Production deployments should use verify-full.
+	DBSSLMode     string
+	DBSSLCert     string
+	DBSSLKey      string
+	DBSSLRootCert string
+
+	// AIProvider selects the chat backend: "openai", "anthropic" or "ollama".
+	AIProvider      string
+	OpenAIAPIKey    string
+	OpenAIModel     string
+	AnthropicAPIKey string
+	AnthropicModel  string
+	OllamaURL       string
+	OllamaModel     string
+
+	JWTSecret string
+
+	// CORS policy, all comma-separated lists. Origins may be "*" only when
+	// credentials are disabled.
+	CORSAllowedOrigins   []string
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSAllowCredentials bool
+	CORSMaxAgeHours      int
+
+	// AdminIPAllowlist is a comma-separated list of CIDR ranges allowed to
+	// reach /api/admin/*. Empty means no restriction (development).
+	AdminIPAllowlist string
+
+	// RouteTimeouts is raw JSON mapping "METHOD /path" patterns to
+	// durations, e.g. {"POST /api/ai/chat": "30s", "GET /health": "2s"}.
+	RouteTimeouts string
+
+	// BookingRefFormat selects the booking reference generator: "v1"
+	// (TRN-YYYY-NNNNN) or "v2" (TRN-YYYYMMDD-<base36 random>).
+	BookingRefFormat string
+
+	// DocumentStoragePath is where ID document scans attached to bookings
+	// are written.
+	DocumentStoragePath string
+
+	// CancellationModelWeights optionally overrides the cancellation risk
+	// coefficients as a JSON map, e.g. {"lead_days": 0.1}.
+	CancellationModelWeights string
+
+	SessionTTLHours        int
+	GroupDiscountThreshold int
+
+	// MaxBookingModifications caps how many times a booking can be modified
+	// (date changes, passenger updates, transfers) before further changes
+	// are refused.
+	MaxBookingModifications int
+
+	// ModifyDateCutoffHours and ModifyPassengerCutoffHours are how long
+	// before departure date changes and passenger-name changes close.
+	ModifyDateCutoffHours      int
+	ModifyPassengerCutoffHours int
+
+	AICircuitBreakerThreshold      int
+	AICircuitBreakerTimeoutSeconds int
+
+	// EnableStressTest gates the booking load simulator; keep it off in
+	// production.
+	EnableStressTest bool
+
+	// AllowTestDataPurge gates DELETE /api/admin/test-data; keep it off in
+	// production.
+	AllowTestDataPurge bool
+}
+
+// Load reads the configuration from the environment, applying defaults
+// suitable for local development.
+func Load() *Config {
+	return &Config{
+		Port: getEnv("PORT", "8080"),
+
+		DBHost:     getEnv("DB_HOST", "localhost"),
+		DBPort:     getEnv("DB_PORT", "5432"),
+		DBUser:     getEnv("DB_USER", "trainbooking"),
+		DBPassword: getEnv("DB_PASSWORD", "trainbooking"),
+		DBName:     getEnv("DB_NAME", "trainbooking"),
+
+		DBMaxOpenConns:           getEnvInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:           getEnvInt("DB_MAX_IDLE_CONNS", 5),
+		DBConnMaxLifetimeMinutes: getEnvInt("DB_CONN_MAX_LIFETIME_MINUTES", 5),
+
+		DBSSLMode:     getEnv("DB_SSL_MODE", "disable"),
+		DBSSLCert:     getEnv("DB_SSL_CERT", ""),
+		DBSSLKey:      getEnv("DB_SSL_KEY", ""),
+		DBSSLRootCert: getEnv("DB_SSL_ROOT_CERT", ""),
+
+		AIProvider:      getEnv("AI_PROVIDER", "ollama"),
+		OpenAIAPIKey:    getEnv("OPENAI_API_KEY", ""),
+		OpenAIModel:     getEnv("OPENAI_MODEL", "gpt-4o-mini"),
+		AnthropicAPIKey: getEnv("ANTHROPIC_API_KEY", ""),
+		AnthropicModel:  getEnv("ANTHROPIC_MODEL", "claude-sonnet-4-20250514"),
+		OllamaURL:       getEnv("OLLAMA_URL", "http://localhost:11434"),
+		OllamaModel:     getEnv("OLLAMA_MODEL", "llama3.1"),
+
+		JWTSecret: getEnv("JWT_SECRET", "dev-secret-change-me"),
+
+		CORSAllowedOrigins:   getEnvList("CORS_ALLOWED_ORIGINS", "http://localhost:3000"),
+		CORSAllowedMethods:   getEnvList("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS"),
+		CORSAllowedHeaders:   getEnvList("CORS_ALLOWED_HEADERS", "Origin,Content-Type,Authorization"),
+		CORSAllowCredentials: getEnv("CORS_ALLOW_CREDENTIALS", "true") == "true",
+		CORSMaxAgeHours:      getEnvInt("CORS_MAX_AGE_HOURS", 12),
+
+		AdminIPAllowlist: getEnv("ADMIN_IP_ALLOWLIST", ""),
+
+		RouteTimeouts: getEnv("ROUTE_TIMEOUTS", ""),
+
+		BookingRefFormat: getEnv("BOOKING_REF_FORMAT", "v1"),
+
+		DocumentStoragePath: getEnv("DOCUMENT_STORAGE_PATH", "./documents"),
+
+		CancellationModelWeights: getEnv("CANCELLATION_MODEL_WEIGHTS", ""),
+
+		SessionTTLHours:        getEnvInt("SESSION_TTL_HOURS", 24),
+		GroupDiscountThreshold: getEnvInt("GROUP_DISCOUNT_THRESHOLD", 10),
+
+		MaxBookingModifications: getEnvInt("MAX_BOOKING_MODIFICATIONS", 2),
+
+		ModifyDateCutoffHours:      getEnvInt("MODIFY_DATE_CUTOFF_HOURS", 24),
+		ModifyPassengerCutoffHours: getEnvInt("MODIFY_PASSENGER_CUTOFF_HOURS", 2),
+
+		AICircuitBreakerThreshold:      getEnvInt("AI_CIRCUIT_BREAKER_THRESHOLD", 5),
+		AICircuitBreakerTimeoutSeconds: getEnvInt("AI_CIRCUIT_BREAKER_TIMEOUT_SECONDS", 60),
+
+		EnableStressTest: getEnv("ENABLE_STRESS_TEST", "false") == "true",
+
+		AllowTestDataPurge: getEnv("ALLOW_TEST_DATA_PURGE", "false") == "true",
+	}
+}
+
+// Validate checks the configuration for inconsistent values.
+func (c *Config) Validate() error {
+	switch c.AIProvider {
+	case "openai", "anthropic", "ollama":
+	default:
+		return fmt.Errorf("invalid AI_PROVIDER %q: must be openai, anthropic or ollama", c.AIProvider)
+	}
+	if c.DBMaxIdleConns > c.DBMaxOpenConns {
+		return fmt.Errorf("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)",
+			c.DBMaxIdleConns, c.DBMaxOpenConns)
+	}
+	switch c.BookingRefFormat {
+	case "v1", "v2":
+	default:
+		return fmt.Errorf("invalid BOOKING_REF_FORMAT %q: must be v1 or v2", c.BookingRefFormat)
+	}
+	switch c.DBSSLMode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		return fmt.Errorf("invalid DB_SSL_MODE %q: must be disable, require, verify-ca or verify-full", c.DBSSLMode)
+	}
+	if c.CORSAllowCredentials {
+		for _, origin := range c.CORSAllowedOrigins {
+			if origin == "*" {
+				return fmt.Errorf("CORS_ALLOW_CREDENTIALS=true cannot be combined with CORS_ALLOWED_ORIGINS=*")
+			}
+		}
+	}
+	if c.CORSMaxAgeHours < 0 {
+		return fmt.Errorf("CORS_MAX_AGE_HOURS must not be negative")
+	}
+	return nil
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// getEnvList reads a comma-separated environment variable, trimming
+// whitespace and dropping empty entries.
+func getEnvList(key, fallback string) []string {
+	raw := getEnv(key, fallback)
+	var out []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func getEnvInt(key string, fallback int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"trainbooking/config"
+)
+
+// DB is the shared connection pool, initialised by Connect.
+var DB *sql.DB
+
+// Connect opens the PostgreSQL connection pool and verifies it with a ping.
+func Connect(cfg *config.Config) error {
+	connStr := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
+	)
+	// lib/pq loads the TLS material itself when the paths are given.
+	if cfg.DBSSLCert != "" {
+		connStr += " sslcert=" + cfg.DBSSLCert
+	}
+	if cfg.DBSSLKey != "" {
+		connStr += " sslkey=" + cfg.DBSSLKey
+	}
+	if cfg.DBSSLRootCert != "" {
+		connStr += " sslrootcert=" + cfg.DBSSLRootCert
+	}
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(cfg.DBConnMaxLifetimeMinutes) * time.Minute)
+
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("pinging database: %w", err)
+	}
+
+	DB = db
+	log.Printf("connected to database %s@%s:%s/%s", cfg.DBUser, cfg.DBHost, cfg.DBPort, cfg.DBName)
+	return nil
+}
+
+// EnsurePgTrgmExtension enables the pg_trgm extension used by fuzzy station
+// matching. It fails when the database user lacks the privilege or the
+// extension is not installed on the server; callers may treat that as
+// non-fatal since FindStationByNameOrCode degrades to ILIKE matching.
+func EnsurePgTrgmExtension(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE EXTENSION IF NOT EXISTS pg_trgm`); err != nil {
+		return fmt.Errorf("enabling pg_trgm: %w", err)
+	}
+	return nil
+}
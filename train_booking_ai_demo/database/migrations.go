@@ -0,0 +1,74 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"log"
+	"sort"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// RunMigrations applies the embedded SQL migrations that have not run yet.
+// Applied files are tracked in schema_migrations by filename; unapplied ones
+// run in lexicographic order, each inside its own transaction, so a failing
+// migration rolls back cleanly and aborts the startup.
+func RunMigrations() error {
+	if _, err := DB.Exec(
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			filename   TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+	); err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return fmt.Errorf("reading embedded migrations: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var applied bool
+		err := DB.QueryRow(
+			`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE filename = $1)`, name,
+		).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("checking migration %s: %w", name, err)
+		}
+		if applied {
+			continue
+		}
+
+		contents, err := migrationFiles.ReadFile("migrations/" + name)
+		if err != nil {
+			return fmt.Errorf("reading migration %s: %w", name, err)
+		}
+
+		tx, err := DB.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(string(contents)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("applying migration %s: %w", name, err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO schema_migrations (filename) VALUES ($1)`, name,
+		); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("recording migration %s: %w", name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("committing migration %s: %w", name, err)
+		}
+		log.Printf("applied migration %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+-- Initial schema and seed data for the train booking AI demo.
+-- Applied automatically by database.RunMigrations at startup.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS stations (
+    id   SERIAL PRIMARY KEY,
+    code VARCHAR(5) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    latitude  FLOAT8,
+    longitude FLOAT8
+);
+
+CREATE INDEX IF NOT EXISTS idx_stations_name_trgm ON stations USING gin (name gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS trains (
+    id          SERIAL PRIMARY KEY,
+    number      VARCHAR(10) UNIQUE NOT NULL,
+    type        VARCHAR(2) NOT NULL CHECK (type IN ('FR', 'IC', 'RG')),
+    total_seats INT NOT NULL,
+    has_wifi    BOOLEAN NOT NULL DEFAULT false,
+    has_food    BOOLEAN NOT NULL DEFAULT false,
+    out_of_service_from  TIMESTAMPTZ,
+    out_of_service_until TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS schedules (
+    id              SERIAL PRIMARY KEY,
+    train_id        INT NOT NULL REFERENCES trains(id),
+    origin_id       INT NOT NULL REFERENCES stations(id),
+    destination_id  INT NOT NULL REFERENCES stations(id),
+    departure_time  TIME NOT NULL,
+    arrival_time    TIME NOT NULL,
+    day_of_week     INT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6),
+    price_base      NUMERIC(8,2) NOT NULL,
+    total_seats     INT NOT NULL,
+    available_seats INT NOT NULL,
+    is_active       BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE TABLE IF NOT EXISTS bookings (
+    id              SERIAL PRIMARY KEY,
+    booking_ref     VARCHAR(30) UNIQUE NOT NULL,
+    schedule_id     INT NOT NULL REFERENCES schedules(id),
+    booking_date    DATE NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'confirmed',
+    total_price     NUMERIC(8,2) NOT NULL,
+    refund_amount   NUMERIC(8,2) NOT NULL DEFAULT 0,
+    passenger_count INT NOT NULL,
+    promo_code      VARCHAR(20),
+    contact_email   VARCHAR(255),
+    -- Paths of ID document scans attached at booking time, one per passenger.
+    documents       TEXT[],
+    -- Chat session that created the booking, if it came through the AI.
+    session_id      VARCHAR(100),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS passengers (
+    id             SERIAL PRIMARY KEY,
+    booking_id     INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    name           VARCHAR(255) NOT NULL,
+    passenger_type VARCHAR(10) NOT NULL DEFAULT 'adult',
+    seat_number    VARCHAR(5),
+    price          NUMERIC(8,2) NOT NULL,
+    email          VARCHAR(255)
+);
+
+CREATE TABLE IF NOT EXISTS booking_events (
+    id          SERIAL PRIMARY KEY,
+    booking_ref VARCHAR(20) NOT NULL,
+    event_type  VARCHAR(50) NOT NULL,
+    details     JSONB,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS conversation_history (
+    id            SERIAL PRIMARY KEY,
+    session_id    VARCHAR(100) NOT NULL,
+    role          VARCHAR(20) NOT NULL,
+    message       TEXT NOT NULL,
+    function_call JSONB,
+    is_anonymized BOOLEAN NOT NULL DEFAULT FALSE,
+    timestamp     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_history_session ON conversation_history (session_id, timestamp);
+CREATE INDEX IF NOT EXISTS idx_conversation_history_message_fts
+    ON conversation_history USING gin (to_tsvector('english', message));
+
+CREATE TABLE IF NOT EXISTS users (
+    id            SERIAL PRIMARY KEY,
+    username      VARCHAR(50) UNIQUE NOT NULL,
+    password_hash VARCHAR(100) NOT NULL,
+    role          VARCHAR(10) NOT NULL DEFAULT 'user',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Operational advisories attached to schedules, shown in search results.
+CREATE TABLE IF NOT EXISTS schedule_alerts (
+    id                 SERIAL PRIMARY KEY,
+    schedule_id        INT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    severity           VARCHAR(10) NOT NULL CHECK (severity IN ('info', 'warning', 'disruption')),
+    message            TEXT NOT NULL,
+    valid_from         TIMESTAMPTZ NOT NULL,
+    valid_to           TIMESTAMPTZ NOT NULL,
+    created_by_user_id INT REFERENCES users(id),
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_alerts_schedule ON schedule_alerts (schedule_id, valid_from, valid_to);
+
+-- Revoked JWT ids, kept until the tokens would have expired anyway.
+CREATE TABLE IF NOT EXISTS jwt_blocklist (
+    jti        UUID PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id            SERIAL PRIMARY KEY,
+    admin_user_id INT NOT NULL,
+    action        VARCHAR(50) NOT NULL,
+    target        TEXT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Seed data ------------------------------------------------------------
+
+INSERT INTO stations (code, name, city, latitude, longitude) VALUES
+    ('MI', 'Milano Centrale',   'Milano',  45.4862,  9.2046),
+    ('RM', 'Roma Termini',      'Roma',    41.9009, 12.5018),
+    ('NA', 'Napoli Centrale',   'Napoli',  40.8518, 14.2725),
+    ('FI', 'Firenze S.M.N.',    'Firenze', 43.7764, 11.2481),
+    ('BO', 'Bologna Centrale',  'Bologna', 44.5058, 11.3426),
+    ('TO', 'Torino Porta Nuova','Torino',  45.0626,  7.6781),
+    ('VE', 'Venezia S. Lucia',  'Venezia', 45.4415, 12.3205)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO trains (number, type, total_seats, has_wifi, has_food) VALUES
+    ('FR9521', 'FR', 400, true,  true),
+    ('FR9544', 'FR', 400, true,  true),
+    ('IC581',  'IC', 250, true,  false),
+    ('IC605',  'IC', 250, false, false),
+    ('RG2041', 'RG', 180, false, false),
+    ('RG2088', 'RG', 180, false, false)
+ON CONFLICT (number) DO NOTHING;
+
+-- One week of example schedules: Milano-Roma both directions plus a few
+-- regional legs, repeated for every day of the week.
+INSERT INTO schedules (train_id, origin_id, destination_id, departure_time, arrival_time, day_of_week, price_base, total_seats, available_seats)
+SELECT t.id, o.id, d.id, s.dep::time, s.arr::time, dow, s.price, t.total_seats, t.total_seats
+FROM (VALUES
+    ('FR9521', 'MI', 'RM', '07:00', '10:10', 89.00),
+    ('FR9544', 'RM', 'MI', '08:30', '11:40', 89.00),
+    ('FR9521', 'MI', 'NA', '14:00', '18:05', 99.00),
+    ('IC581',  'MI', 'BO', '09:15', '11:30', 29.00),
+    ('IC605',  'BO', 'FI', '12:00', '13:05', 19.00),
+    ('RG2041', 'FI', 'RM', '15:20', '18:40', 24.00),
+    ('RG2088', 'TO', 'MI', '06:40', '08:25', 14.50)
+) AS s(train, origin, dest, dep, arr, price)
+JOIN trains t ON t.number = s.train
+JOIN stations o ON o.code = s.origin
+JOIN stations d ON d.code = s.dest
+CROSS JOIN generate_series(0, 6) AS dow
+WHERE NOT EXISTS (SELECT 1 FROM schedules LIMIT 1);
+
+-- Default admin user, password "password" (bcrypt). Change in production.
+INSERT INTO users (username, password_hash, role) VALUES
+    ('admin', '$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy', 'admin')
+ON CONFLICT (username) DO NOTHING;
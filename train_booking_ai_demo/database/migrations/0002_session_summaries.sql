@@ -0,0 +1,11 @@
+-- Rolling AI-generated summaries of chat sessions, used to keep long
+-- conversations coherent once the history window truncates older turns.
+CREATE TABLE IF NOT EXISTS session_summaries (
+    id                            SERIAL PRIMARY KEY,
+    session_id                    VARCHAR(100) NOT NULL,
+    summary                       TEXT NOT NULL,
+    message_count_at_summary_time INT NOT NULL,
+    created_at                    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_summaries_session ON session_summaries (session_id, created_at DESC);
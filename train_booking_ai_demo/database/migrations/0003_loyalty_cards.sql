@@ -0,0 +1,19 @@
+-- Loyalty programme: cards with a points balance and a tier, linked to
+-- passengers by card number at booking time.
+CREATE TABLE IF NOT EXISTS loyalty_cards (
+    card_number    VARCHAR(20) PRIMARY KEY,
+    passenger_name VARCHAR(255) NOT NULL,
+    points_balance INT NOT NULL DEFAULT 0,
+    tier           VARCHAR(10) NOT NULL DEFAULT 'bronze' CHECK (tier IN ('bronze', 'silver', 'gold')),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE passengers ADD COLUMN IF NOT EXISTS loyalty_card_number VARCHAR(20) REFERENCES loyalty_cards(card_number);
+
+CREATE INDEX IF NOT EXISTS idx_passengers_loyalty_card ON passengers (loyalty_card_number);
+
+INSERT INTO loyalty_cards (card_number, passenger_name, points_balance, tier) VALUES
+    ('CF-00012345', 'Mario Rossi',    1250, 'silver'),
+    ('CF-00067890', 'Anna Bianchi',   4800, 'gold'),
+    ('CF-00024680', 'Luca Verdi',      150, 'bronze')
+ON CONFLICT (card_number) DO NOTHING;
@@ -0,0 +1,8 @@
+-- Audit log of runtime AI provider switches.
+CREATE TABLE IF NOT EXISTS provider_changes (
+    id            SERIAL PRIMARY KEY,
+    from_provider VARCHAR(20) NOT NULL,
+    to_provider   VARCHAR(20) NOT NULL,
+    reason        TEXT,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
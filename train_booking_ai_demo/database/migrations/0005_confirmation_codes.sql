@@ -0,0 +1,2 @@
+-- 4-digit PIN for verbal booking verification at the call centre.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS confirmation_code VARCHAR(4);
@@ -0,0 +1,18 @@
+-- Mock payment processing: bookings carry a payment status and every payment
+-- attempt is recorded. Existing bookings were instantly confirmed, hence the
+-- 'paid' default.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS payment_status VARCHAR(10) NOT NULL DEFAULT 'paid'
+    CHECK (payment_status IN ('pending', 'paid', 'failed', 'refunded'));
+
+CREATE TABLE IF NOT EXISTS payments (
+    id           SERIAL PRIMARY KEY,
+    booking_id   INT NOT NULL REFERENCES bookings(id),
+    amount       NUMERIC(8,2) NOT NULL,
+    currency     VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    provider     VARCHAR(20) NOT NULL,
+    provider_ref VARCHAR(40) UNIQUE NOT NULL,
+    status       VARCHAR(10) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_booking ON payments (booking_id);
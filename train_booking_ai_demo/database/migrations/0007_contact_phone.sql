@@ -0,0 +1,3 @@
+-- Phone number alongside the contact email, so agents can reach passengers
+-- about disruptions.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS contact_phone VARCHAR(20);
@@ -0,0 +1,13 @@
+-- Price change audit trail for schedules. Rows are written by
+-- services.UpdateSchedule whenever price_base changes.
+CREATE TABLE IF NOT EXISTS schedule_price_history (
+    id                  SERIAL PRIMARY KEY,
+    schedule_id         INT NOT NULL REFERENCES schedules(id),
+    old_price           NUMERIC(8,2) NOT NULL,
+    new_price           NUMERIC(8,2) NOT NULL,
+    changed_by_user_id  INT,
+    changed_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_schedule
+    ON schedule_price_history (schedule_id, changed_at DESC);
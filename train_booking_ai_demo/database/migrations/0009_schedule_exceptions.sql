@@ -0,0 +1,10 @@
+-- Per-date exceptions to the weekly schedule pattern, e.g. a Monday service
+-- cancelled on a public holiday.
+CREATE TABLE IF NOT EXISTS schedule_exceptions (
+    id              SERIAL PRIMARY KEY,
+    schedule_id     INT NOT NULL REFERENCES schedules(id),
+    exception_date  DATE NOT NULL,
+    exception_type  VARCHAR(10) NOT NULL CHECK (exception_type IN ('cancelled', 'modified')),
+    reason          TEXT,
+    UNIQUE (schedule_id, exception_date)
+);
@@ -0,0 +1,3 @@
+-- Client-supplied context attached to chat turns (e.g. the search widget's
+-- selected route), stored alongside the message it accompanied.
+ALTER TABLE conversation_history ADD COLUMN IF NOT EXISTS metadata JSONB;
@@ -0,0 +1,6 @@
+-- IANA timezone per station, for timezone-aware time display. The current
+-- network is entirely Italian, so everything seeds to Europe/Rome; future
+-- international stations override it.
+ALTER TABLE stations ADD COLUMN IF NOT EXISTS timezone VARCHAR(50) DEFAULT 'Europe/Rome';
+
+UPDATE stations SET timezone = 'Europe/Rome' WHERE timezone IS NULL;
@@ -0,0 +1,4 @@
+-- v2 booking references (TRN-YYYYMMDD-xxxxxxxx) are 21 characters, one more
+-- than booking_events.booking_ref held, so recording any event for a v2
+-- booking failed. Match the VARCHAR(30) the bookings table uses.
+ALTER TABLE booking_events ALTER COLUMN booking_ref TYPE VARCHAR(30);
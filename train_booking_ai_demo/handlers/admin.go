@@ -0,0 +1,1103 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/database"
+	"trainbooking/maintenance"
+	"trainbooking/models"
+	"trainbooking/services"
+)
+
+// GetScheduleManifest handles GET /api/admin/schedules/:id/manifest?date=…
+// It returns the passenger manifest for one schedule and date, as JSON or,
+// with format=csv, as a flat CSV passenger list for printing.
+func GetScheduleManifest(c *gin.Context) {
+	scheduleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+	date := c.Query("date")
+	if date == "" {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("date query parameter is required"))
+		return
+	}
+
+	bookings, err := services.GetBookingsByScheduleAndDate(scheduleID, date)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	trainNumber, route, err := services.GetScheduleSummary(scheduleID)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeManifestCSV(c, scheduleID, date, bookings)
+		return
+	}
+
+	totalPassengers := 0
+	for _, b := range bookings {
+		totalPassengers += len(b.Passengers)
+	}
+	c.JSON(http.StatusOK, models.ManifestResponse{
+		ScheduleID:      scheduleID,
+		Date:            date,
+		TrainNumber:     trainNumber,
+		Route:           route,
+		Bookings:        bookings,
+		TotalPassengers: totalPassengers,
+	})
+}
+
+// DeleteSession handles DELETE /api/admin/sessions/:id. It hard-deletes the
+// conversation history of one session and records the deletion in the admin
+// audit log.
+func DeleteSession(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	deleted, err := services.DeleteSession(sessionID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	if deleted == 0 {
+		errorResponse(c, http.StatusNotFound, fmt.Errorf("session %s not found", sessionID))
+		return
+	}
+
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "delete_session", sessionID); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"session_id": sessionID, "deleted_messages": deleted})
+}
+
+// CheckBookingPrice handles GET /api/admin/bookings/:ref/price-check. It
+// shows what an existing booking would cost under the current fare rules.
+func CheckBookingPrice(c *gin.Context) {
+	report, err := services.CheckBookingPrice(c.Param("ref"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetCancellationRisk handles GET /api/admin/bookings/:ref/cancellation-risk.
+func GetCancellationRisk(c *gin.Context) {
+	risk, err := services.PredictBookingCancellationRisk(c.Param("ref"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"booking_ref":       c.Param("ref"),
+		"cancellation_risk": risk,
+	})
+}
+
+// SetTrainOutOfService handles POST /api/admin/trains/:id/out-of-service.
+// Affected confirmed bookings in the window are cancelled with full refund.
+func SetTrainOutOfService(c *gin.Context) {
+	trainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid train id"))
+		return
+	}
+
+	var req struct {
+		From   time.Time `json:"from" binding:"required"`
+		Until  time.Time `json:"until" binding:"required"`
+		Reason string    `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	cancelled, err := services.SetTrainOutOfService(trainID, req.From, req.Until, req.Reason)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"train_id":           trainID,
+		"cancelled_bookings": cancelled,
+	})
+}
+
+// ClearTrainOutOfService handles DELETE /api/admin/trains/:id/out-of-service.
+func ClearTrainOutOfService(c *gin.Context) {
+	trainID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid train id"))
+		return
+	}
+	if err := services.ClearTrainOutOfService(trainID); err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"train_id": trainID, "out_of_service": false})
+}
+
+// SearchConversations handles
+// GET /api/admin/conversations/search?q=…&from=…&to=… for support agents.
+func SearchConversations(c *gin.Context) {
+	q := c.Query("q")
+	if q == "" {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("q query parameter is required"))
+		return
+	}
+
+	from := time.Time{}
+	to := time.Now()
+	if s := c.Query("from"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid from date"))
+			return
+		}
+		from = parsed
+	}
+	if s := c.Query("to"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid to date"))
+			return
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	results, err := services.SearchConversations(q, from, to)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"query": q, "results": results})
+}
+
+// GetOccupancyHeatmap handles GET /api/admin/heatmap?month=10&year=2025.
+func GetOccupancyHeatmap(c *gin.Context) {
+	month, err1 := strconv.Atoi(c.Query("month"))
+	year, err2 := strconv.Atoi(c.Query("year"))
+	if err1 != nil || err2 != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("month and year query parameters are required"))
+		return
+	}
+
+	cells, err := services.GetScheduleOccupancyHeatmap(month, year)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"month": month, "year": year, "cells": cells})
+}
+
+// CreateSchedule handles POST /api/admin/schedules. A time overlap with an
+// existing schedule for the same train answers 409 with the conflicting id.
+func CreateSchedule(c *gin.Context) {
+	var req models.ScheduleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	schedule, err := services.CreateSchedule(req)
+	if err != nil {
+		respondScheduleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// UpdateSchedule handles PUT /api/admin/schedules/:id.
+func UpdateSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+	var req models.ScheduleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	schedule, err := services.UpdateSchedule(id, req, c.GetInt("user_id"))
+	if err != nil {
+		respondScheduleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateSchedulePricing handles PATCH /api/admin/schedules/:id/pricing with
+// {"price_base": 49.90}, changing only the price and recording it in the
+// price history.
+func UpdateSchedulePricing(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+	var req struct {
+		PriceBase float64 `json:"price_base" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := services.UpdateSchedulePricing(id, req.PriceBase, c.GetInt("user_id")); err != nil {
+		respondScheduleError(c, err)
+		return
+	}
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "update_schedule_pricing",
+		fmt.Sprintf("schedule %d -> %.2f", id, req.PriceBase)); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedule_id": id, "price_base": req.PriceBase})
+}
+
+// CopyScheduleToTrain handles POST /api/admin/schedules/:id/copy-to-train
+// with {"train_id":5}, recreating a retiring train's schedule on its
+// replacement.
+func CopyScheduleToTrain(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+	var req struct {
+		TrainID int `json:"train_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	schedule, err := services.CopyScheduleToNewTrain(id, req.TrainID)
+	if err != nil {
+		respondScheduleError(c, err)
+		return
+	}
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "copy_schedule",
+		fmt.Sprintf("schedule %d -> %d (train %d)", id, schedule.ID, req.TrainID)); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// BulkPriceUpdate handles POST /api/admin/schedules/bulk-price-update. With
+// dry_run set it only reports what would change.
+func BulkPriceUpdate(c *gin.Context) {
+	var req services.BulkPriceUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := services.BulkUpdatePrices(req)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if !req.DryRun {
+		if err := services.RecordAdminAction(c.GetInt("user_id"), "bulk_price_update",
+			fmt.Sprintf("%s %+.2f: %d schedules", req.AdjustmentType, req.AdjustmentValue, result.UpdatedCount)); err != nil {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteSchedule handles DELETE /api/admin/schedules/:id (soft delete).
+func DeleteSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+	if err := services.DeactivateSchedule(id); err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedule_id": id, "is_active": false})
+}
+
+func respondScheduleError(c *gin.Context, err error) {
+	var conflict *services.ScheduleConflictError
+	if errors.As(err, &conflict) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":                     err.Error(),
+			"conflict_with_schedule_id": conflict.ScheduleID,
+		})
+		return
+	}
+	var validation services.ScheduleValidationErrors
+	if errors.As(err, &validation) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  err.Error(),
+			"errors": validation,
+		})
+		return
+	}
+	errorResponse(c, http.StatusUnprocessableEntity, err)
+}
+
+// GetFrequentTravellers handles
+// GET /api/admin/analytics/frequent-travellers?limit=20&min_bookings=2.
+func GetFrequentTravellers(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	minBookings, _ := strconv.Atoi(c.DefaultQuery("min_bookings", "1"))
+
+	travellers, err := services.GetFrequentTravellers(limit, minBookings)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"travellers": travellers})
+}
+
+// GetScheduleRevenue handles
+// GET /api/admin/schedules/:id/revenue?from=2025-10-01&to=2025-10-31.
+// Without dates it covers the last 30 days.
+func GetScheduleRevenue(c *gin.Context) {
+	scheduleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+
+	from := time.Now().AddDate(0, 0, -30)
+	to := time.Now()
+	if s := c.Query("from"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid from date"))
+			return
+		}
+		from = parsed
+	}
+	if s := c.Query("to"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid to date"))
+			return
+		}
+		to = parsed
+	}
+
+	report, err := services.GetScheduleRevenue(scheduleID, from, to)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// RecalculateSeats handles POST /api/admin/schedules/:id/recalculate-seats,
+// repairing a drifted available_seats counter. correction is 0 when the
+// counter was already right.
+func RecalculateSeats(c *gin.Context) {
+	scheduleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+
+	delta, err := services.RecalculateAvailableSeats(scheduleID)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedule_id": scheduleID, "correction": delta})
+}
+
+// AddScheduleException handles POST /api/admin/schedules/:id/exceptions with
+// {"date":"2025-12-25","exception_type":"cancelled","reason":"public holiday"}.
+func AddScheduleException(c *gin.Context) {
+	scheduleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+	var req struct {
+		Date          string `json:"date" binding:"required"`
+		ExceptionType string `json:"exception_type" binding:"required"`
+		Reason        string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := services.AddScheduleException(scheduleID, req.Date, req.ExceptionType, req.Reason); err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "add_schedule_exception",
+		fmt.Sprintf("schedule %d %s on %s", scheduleID, req.ExceptionType, req.Date)); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"schedule_id": scheduleID, "date": req.Date, "exception_type": req.ExceptionType,
+	})
+}
+
+// DeleteScheduleException handles
+// DELETE /api/admin/schedules/:id/exceptions/:date.
+func DeleteScheduleException(c *gin.Context) {
+	scheduleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+	date := c.Param("date")
+
+	if err := services.RemoveScheduleException(scheduleID, date); err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "remove_schedule_exception",
+		fmt.Sprintf("schedule %d on %s", scheduleID, date)); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedule_id": scheduleID, "date": date})
+}
+
+// GetGroupBookingReport handles
+// GET /api/admin/schedules/:id/group-report?date=2025-10-20.
+func GetGroupBookingReport(c *gin.Context) {
+	scheduleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+	date := c.Query("date")
+	if date == "" {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("date query parameter is required"))
+		return
+	}
+
+	report, err := services.GenerateGroupBookingReport(scheduleID, date)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetSchedulePriceHistory handles
+// GET /api/admin/schedules/:id/price-history?months=12.
+func GetSchedulePriceHistory(c *gin.Context) {
+	scheduleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+	months, _ := strconv.Atoi(c.DefaultQuery("months", "12"))
+
+	history, err := services.GetScheduleHistoricalPrices(scheduleID, months)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// CheckOverbooking handles
+// GET /api/admin/schedules/:id/overbooking-check?date=2025-10-20.
+func CheckOverbooking(c *gin.Context) {
+	scheduleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+	date := c.Query("date")
+	if date == "" {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("date query parameter is required"))
+		return
+	}
+
+	report, err := services.CheckOverbooking(scheduleID, date)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetScheduleLoadFactor handles GET /api/admin/schedules/:id/load-factor?weeks=4,
+// reporting average, peak and minimum occupancy over the schedule's recent
+// operating days.
+func GetScheduleLoadFactor(c *gin.Context) {
+	scheduleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid schedule id"))
+		return
+	}
+
+	weeks := 4
+	if s := c.Query("weeks"); s != "" {
+		weeks, err = strconv.Atoi(s)
+		if err != nil || weeks < 1 || weeks > 52 {
+			errorResponse(c, http.StatusBadRequest, fmt.Errorf("weeks must be between 1 and 52"))
+			return
+		}
+	}
+
+	report, err := services.GetScheduleLoadFactorReport(scheduleID, weeks)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetConversationFlowStats handles
+// GET /api/admin/analytics/conversation-flow, the data behind the
+// conversation Sankey diagram.
+func GetConversationFlowStats(c *gin.Context) {
+	nodes, err := services.GetConversationFlowStats()
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"transitions": nodes})
+}
+
+// GetTrainTypeStats handles
+// GET /api/admin/analytics/train-types?from=2025-10-01&to=2025-10-31.
+// Without dates it covers the last 30 days.
+func GetTrainTypeStats(c *gin.Context) {
+	from, to, err := dateRangeQuery(c, 30)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	stats, err := services.GetTrainTypeStats(from, to)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"train_types": stats})
+}
+
+// GetAIFunctionCallStats handles
+// GET /api/admin/analytics/function-calls?from=2025-10-01&to=2025-10-31.
+// Without dates it covers the last 30 days.
+func GetAIFunctionCallStats(c *gin.Context) {
+	from := time.Now().AddDate(0, 0, -30)
+	to := time.Now()
+	if s := c.Query("from"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid from date"))
+			return
+		}
+		from = parsed
+	}
+	if s := c.Query("to"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid to date"))
+			return
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	stats, err := services.GetAIFunctionCallStats(from, to)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "functions": stats})
+}
+
+// AnonymizeSessions handles POST /api/admin/gdpr/anonymize with
+// {"older_than_days": 90}. The default window is 90 days.
+func AnonymizeSessions(c *gin.Context) {
+	req := struct {
+		OlderThanDays int `json:"older_than_days"`
+	}{OlderThanDays: 90}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.OlderThanDays < 1 {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("older_than_days must be positive"))
+		return
+	}
+
+	count, err := services.AnonymizeExpiredSessions(time.Duration(req.OlderThanDays) * 24 * time.Hour)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "gdpr_anonymize",
+		fmt.Sprintf("older_than_days=%d rows=%d", req.OlderThanDays, count)); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"anonymized_rows": count})
+}
+
+// PurgeTestData handles DELETE /api/admin/test-data?prefix=TEST-. It is
+// enabled only when ALLOW_TEST_DATA_PURGE is set, since it hard-deletes
+// bookings.
+func PurgeTestData(allowed bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !allowed {
+			errorResponse(c, http.StatusForbidden, fmt.Errorf("test data purging is disabled"))
+			return
+		}
+		prefix := c.DefaultQuery("prefix", "TEST-")
+
+		deleted, err := services.PurgeTestData(prefix)
+		if err != nil {
+			errorResponse(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+		if err := services.RecordAdminAction(c.GetInt("user_id"), "purge_test_data",
+			fmt.Sprintf("prefix=%s rows=%d", prefix, deleted)); err != nil {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"prefix": prefix, "deleted_rows": deleted})
+	}
+}
+
+// StressTestBookings handles POST /api/admin/stress-test/bookings. It is
+// enabled only when ENABLE_STRESS_TEST is set, since it creates real
+// bookings.
+func StressTestBookings(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			errorResponse(c, http.StatusForbidden, fmt.Errorf("stress testing is disabled"))
+			return
+		}
+		var req struct {
+			ScheduleID int    `json:"schedule_id" binding:"required"`
+			Date       string `json:"date" binding:"required"`
+			N          int    `json:"n" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			errorResponse(c, http.StatusBadRequest, err)
+			return
+		}
+
+		refs, err := services.SimulateBookingLoad(req.ScheduleID, req.Date, req.N)
+		if err != nil {
+			errorResponse(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"attempted":  req.N,
+			"successful": len(refs),
+			"bookings":   refs,
+		})
+	}
+}
+
+// GetStationReport handles GET /api/admin/stations/:code/report.
+func GetStationReport(c *gin.Context) {
+	report, err := services.GenerateStationReport(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ReindexStations handles POST /api/admin/stations/reindex, rebuilding the
+// trigram index behind fuzzy station matching.
+func ReindexStations(c *gin.Context) {
+	if err := services.ReindexStationSimilarity(database.DB); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"reindexed": "idx_stations_name_trgm"})
+}
+
+// GetDatabaseStats handles GET /api/admin/db/stats, exposing the connection
+// pool counters for capacity tuning.
+func GetDatabaseStats(c *gin.Context) {
+	stats := database.DB.Stats()
+	c.JSON(http.StatusOK, gin.H{
+		"max_open_connections": stats.MaxOpenConnections,
+		"open_connections":     stats.OpenConnections,
+		"in_use":               stats.InUse,
+		"idle":                 stats.Idle,
+		"wait_count":           stats.WaitCount,
+		"wait_duration_ms":     stats.WaitDuration.Milliseconds(),
+		"max_idle_closed":      stats.MaxIdleClosed,
+		"max_lifetime_closed":  stats.MaxLifetimeClosed,
+	})
+}
+
+// RunDatabaseQuery handles POST /api/admin/db/query, a read-only escape
+// hatch for support. Every query is written to the admin audit log.
+func RunDatabaseQuery(c *gin.Context) {
+	var req struct {
+		Query string `json:"query" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "db_query", req.Query); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	rows, err := services.TestDatabaseQuery(req.Query)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"row_count": len(rows), "rows": rows})
+}
+
+// GetBookingsByDay handles
+// GET /api/admin/analytics/bookings-by-day?from=2025-01-01&to=2025-12-31.
+func GetBookingsByDay(c *gin.Context) {
+	from, to, err := dateRangeQuery(c, 30)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	series, err := services.GetBookingCountByDay(from, to)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"days": series})
+}
+
+// GetBookingsByDaySummary handles
+// GET /api/admin/analytics/bookings-by-day/summary, the weekly rollup.
+func GetBookingsByDaySummary(c *gin.Context) {
+	from, to, err := dateRangeQuery(c, 30)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	series, err := services.GetBookingCountByWeek(from, to)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"weeks": series})
+}
+
+// ExportBookings handles GET /api/admin/bookings/export?from=&to=&status=.
+// It streams a CSV of all bookings in the range, one row per passenger,
+// defaulting to the last 30 days.
+func ExportBookings(c *gin.Context) {
+	from, to, err := dateRangeQuery(c, 30)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="bookings-%s.csv"`, from.Format("2006-01")))
+
+	if _, err := services.ExportBookingsCSV(from, to, c.Query("status"), c.Writer); err != nil {
+		// Headers are already out; all we can do is log and cut the stream.
+		log.Printf("exporting bookings CSV: %v", err)
+		c.Abort()
+	}
+}
+
+// GetPassengerHistory handles
+// GET /api/admin/passengers/history?name=Marco+Rossi&page=1&page_size=20.
+// The response warns when other stored spellings look like the same person.
+func GetPassengerHistory(c *gin.Context) {
+	name := c.Query("name")
+	if name == "" {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("name is required"))
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	records, err := services.GetPassengerTravelHistory(name, page, pageSize)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	variations, err := services.FindSimilarPassengerNames(name)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := gin.H{"records": records, "page": page, "page_size": pageSize}
+	if len(variations) > 0 {
+		resp["warning"] = "other passengers with similar names exist"
+		resp["similar_names"] = variations
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// BroadcastNotification handles POST /api/admin/notifications/broadcast with
+// {"message":"all trains delayed due to weather","severity":"warning"},
+// pushing the announcement into every active chat session.
+func BroadcastNotification(c *gin.Context) {
+	var req struct {
+		Message  string `json:"message" binding:"required"`
+		Severity string `json:"severity"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.Severity == "" {
+		req.Severity = "info"
+	}
+
+	sessions, err := services.BroadcastMessage(req.Message, req.Severity)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "broadcast_notification",
+		fmt.Sprintf("%s to %d sessions", req.Severity, sessions)); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions_notified": sessions, "severity": req.Severity})
+}
+
+// PurgeAbandonedConversations handles
+// POST /api/admin/conversations/purge-abandoned with {"older_than_hours":72},
+// deleting old sessions that never produced a booking.
+func PurgeAbandonedConversations(c *gin.Context) {
+	var req struct {
+		OlderThanHours int `json:"older_than_hours" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	sessions, messages, err := services.PurgeOrphanedConversations(time.Duration(req.OlderThanHours) * time.Hour)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "purge_abandoned_conversations",
+		fmt.Sprintf("older than %dh: %d sessions, %d messages", req.OlderThanHours, sessions, messages)); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"purged_session_count": sessions,
+		"purged_message_count": messages,
+	})
+}
+
+// RunAIBatchTest handles POST /api/admin/ai/batch-response-test, running the
+// embedded AI regression prompts against the active provider.
+func RunAIBatchTest(c *gin.Context) {
+	report, err := services.RunAIBatchTest(c.Request.Context())
+	if err != nil {
+		errorResponse(c, http.StatusBadGateway, err)
+		return
+	}
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "ai_batch_test",
+		fmt.Sprintf("%d/%d passed", report.Passed, report.Total)); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetHighValueBookings handles
+// GET /api/admin/bookings/high-value?threshold=500&from=&to=&page=1&include_cancelled=false.
+func GetHighValueBookings(c *gin.Context) {
+	threshold, err := strconv.ParseFloat(c.DefaultQuery("threshold", "500"), 64)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid threshold"))
+		return
+	}
+	from, to, err := dateRangeQuery(c, 90)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	includeCancelled, _ := strconv.ParseBool(c.DefaultQuery("include_cancelled", "false"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	bookings, summary, err := services.GetHighValueBookings(threshold, from, to, includeCancelled, page, pageSize)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"count":         summary.Count,
+		"total_revenue": summary.TotalRevenue,
+		"avg_price":     summary.AvgPrice,
+		"page":          page,
+		"page_size":     pageSize,
+		"bookings":      bookings,
+	})
+}
+
+// GetCapacityReport handles
+// GET /api/admin/capacity-report?from=2025-10-01&to=2025-10-31&group_by=week.
+func GetCapacityReport(c *gin.Context) {
+	from, to, err := dateRangeQuery(c, 30)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	records, err := services.GetScheduleCapacityReport(from, to, c.DefaultQuery("group_by", "week"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"records": records})
+}
+
+// SwitchAIProvider handles POST /api/admin/ai/switch-provider with
+// {"provider":"openai","reason":"…"}. New chat turns use the new provider
+// immediately; turns in flight finish on the old one.
+func SwitchAIProvider(c *gin.Context) {
+	var req struct {
+		Provider string `json:"provider" binding:"required"`
+		Reason   string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	from := services.ActiveAIProvider()
+	if err := services.MigrateAIProvider(from, req.Provider, req.Reason); err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "switch_ai_provider",
+		fmt.Sprintf("%s -> %s", from, req.Provider)); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"provider": req.Provider, "previous": from})
+}
+
+// ReplayConversation handles POST /api/admin/ai/replay with
+// {"session_id":"…","dry_run":true}. It re-runs the session's user messages
+// through the current AI provider and reports how the function calls differ
+// from the original, so provider changes can be verified against real
+// conversations.
+func ReplayConversation(c *gin.Context) {
+	var req struct {
+		SessionID string `json:"session_id" binding:"required"`
+		DryRun    bool   `json:"dry_run"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := services.ReplayConversation(c.Request.Context(), req.SessionID, req.DryRun)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "replay_conversation",
+		fmt.Sprintf("%s dry_run=%t", req.SessionID, req.DryRun)); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// SetMaintenanceMode handles POST /api/admin/maintenance-mode with
+// {"enabled":true,"message":"…","estimated_end_utc":"…"}. While enabled, all
+// non-exempt routes answer 503.
+func SetMaintenanceMode(c *gin.Context) {
+	var req struct {
+		Enabled         bool   `json:"enabled"`
+		Message         string `json:"message"`
+		EstimatedEndUTC string `json:"estimated_end_utc"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	maintenance.SetMode(req.Enabled, req.Message, req.EstimatedEndUTC)
+	if err := services.RecordAdminAction(c.GetInt("user_id"), "maintenance_mode",
+		fmt.Sprintf("enabled=%t", req.Enabled)); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": req.Enabled})
+}
+
+// dateRangeQuery parses the from/to query parameters (YYYY-MM-DD), falling
+// back to the last fallbackDays days. to is exclusive of the next day.
+func dateRangeQuery(c *gin.Context, fallbackDays int) (time.Time, time.Time, error) {
+	from := time.Now().AddDate(0, 0, -fallbackDays)
+	to := time.Now()
+	if s := c.Query("from"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid from date")
+		}
+		from = parsed
+	}
+	if s := c.Query("to"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid to date")
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+	return from, to, nil
+}
+
+func writeManifestCSV(c *gin.Context, scheduleID int, date string, bookings []models.Booking) {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="manifest-%d-%s.csv"`, scheduleID, date))
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"booking_ref", "name", "passenger_type", "seat_number", "price"})
+	for _, b := range bookings {
+		for _, p := range b.Passengers {
+			w.Write([]string{
+				b.BookingRef, p.Name, p.PassengerType, p.SeatNumber,
+				strconv.FormatFloat(p.Price, 'f', 2, 64),
+			})
+		}
+	}
+	w.Flush()
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/models"
+	"trainbooking/services"
+)
+
+// CreateAlert handles POST /api/admin/alerts.
+func CreateAlert(c *gin.Context) {
+	var alert models.ScheduleAlert
+	if err := c.ShouldBindJSON(&alert); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	alert.CreatedByUserID = c.GetInt("user_id")
+
+	created, err := services.CreateScheduleAlert(alert)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// ListScheduleAlerts handles GET /api/admin/alerts?schedule_id=….
+func ListScheduleAlerts(c *gin.Context) {
+	scheduleID, err := strconv.Atoi(c.Query("schedule_id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("schedule_id query parameter is required"))
+		return
+	}
+	alerts, err := services.GetScheduleAlerts(scheduleID)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedule_id": scheduleID, "alerts": alerts})
+}
+
+// UpdateAlert handles PUT /api/admin/alerts/:id.
+func UpdateAlert(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid alert id"))
+		return
+	}
+	var alert models.ScheduleAlert
+	if err := c.ShouldBindJSON(&alert); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := services.UpdateScheduleAlert(id, alert); err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"alert_id": id, "updated": true})
+}
+
+// DeleteAlert handles DELETE /api/admin/alerts/:id.
+func DeleteAlert(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid alert id"))
+		return
+	}
+	if err := services.DeleteScheduleAlert(id); err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"alert_id": id, "deleted": true})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/services"
+)
+
+// GetPeakHours handles GET /api/analytics/peak-hours?origin=MI&destination=RM.
+// It is public so the frontend can show "popular departure times" hints.
+func GetPeakHours(c *gin.Context) {
+	origin := c.Query("origin")
+	destination := c.Query("destination")
+	if origin == "" || destination == "" {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("origin and destination are required"))
+		return
+	}
+
+	stats, err := services.GetSchedulePeakHours(origin, destination)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	busiest, quietest := 0, 0
+	for hour, s := range stats {
+		if s.BookingCount > stats[busiest].BookingCount {
+			busiest = hour
+		}
+		if s.BookingCount < stats[quietest].BookingCount {
+			quietest = hour
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"hours":         stats,
+		"busiest_hour":  busiest,
+		"quietest_hour": quietest,
+	})
+}
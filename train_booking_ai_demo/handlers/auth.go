@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+
+	"trainbooking/database"
+	"trainbooking/middleware"
+	"trainbooking/models"
+	"trainbooking/services"
+)
+
+// Login handles POST /api/auth/login and issues a JWT on success.
+func Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	var user models.User
+	err := database.DB.QueryRow(
+		`SELECT id, username, password_hash, role FROM users WHERE username = $1`,
+		req.Username,
+	).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role)
+	if err == sql.ErrNoRows {
+		errorResponse(c, http.StatusUnauthorized, fmt.Errorf("invalid credentials"))
+		return
+	}
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
+		errorResponse(c, http.StatusUnauthorized, fmt.Errorf("invalid credentials"))
+		return
+	}
+
+	token, err := middleware.IssueToken(user.ID, user.Role, 12*time.Hour)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, models.LoginResponse{Token: token, Role: user.Role})
+}
+
+// Logout handles POST /api/auth/logout (authenticated). The token's jti is
+// blocklisted until its natural expiry, after which the hourly prune drops
+// it.
+func Logout(c *gin.Context) {
+	jti := c.GetString("jti")
+	if jti == "" {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("token carries no jti"))
+		return
+	}
+	expiresAt := time.Unix(c.GetInt64("token_exp"), 0)
+
+	if err := services.BlockToken(jti, expiresAt); err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"logged_out": true})
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/models"
+	"trainbooking/services"
+)
+
+var errMissingSessionID = errors.New("session_id query parameter is required")
+
+// CreateBooking handles POST /api/bookings.
+func CreateBooking(c *gin.Context) {
+	var req models.BookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	booking, err := services.CreateBooking(req)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusCreated, booking)
+}
+
+// GetRecentBookings handles GET /api/bookings/recent. It returns the latest
+// bookings made in a chat session, newest first.
+func GetRecentBookings(c *gin.Context) {
+	sessionID := c.Query("session_id")
+	if sessionID == "" {
+		errorResponse(c, http.StatusBadRequest, errMissingSessionID)
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+
+	bookings, err := services.GetRecentBookingsBySession(sessionID, limit)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, bookings)
+}
+
+// VerifyBooking handles GET /api/bookings/verify?ref=TRN-…&code=1234. It is
+// unauthenticated (call-centre verification) and rate-limited per IP in the
+// router.
+func VerifyBooking(c *gin.Context) {
+	ref, code := c.Query("ref"), c.Query("code")
+	if ref == "" || code == "" {
+		errorResponse(c, http.StatusBadRequest, errors.New("ref and code query parameters are required"))
+		return
+	}
+
+	valid, status, err := services.VerifyBookingCode(ref, code)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	resp := gin.H{"valid": valid}
+	if valid {
+		resp["booking_ref"] = ref
+		resp["status"] = status
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetBooking handles GET /api/bookings/:ref.
+func GetBooking(c *gin.Context) {
+	booking, err := services.GetBooking(c.Param("ref"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
+
+// CancelBooking handles DELETE /api/bookings/:ref.
+func CancelBooking(c *gin.Context) {
+	booking, err := services.CancelBooking(c.Param("ref"))
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
+
+// DuplicateBooking handles POST /api/bookings/:ref/duplicate, rebooking the
+// same schedule and passengers on a new date.
+func DuplicateBooking(c *gin.Context) {
+	var req struct {
+		NewDate string `json:"new_date" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	booking, err := services.DuplicateBooking(c.Param("ref"), req.NewDate)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusCreated, booking)
+}
+
+// GetBookingContact handles GET /api/bookings/:ref/contact. Admin tokens get
+// the contact details unmasked; everyone else gets the masked version, which
+// the booking owner can still recognise.
+func GetBookingContact(c *gin.Context) {
+	ref := c.Param("ref")
+
+	var info services.ContactInfo
+	var err error
+	masked := c.GetString("role") != "admin"
+	if masked {
+		info, err = services.GetBookingContactInfoMasked(ref)
+	} else {
+		info, err = services.GetBookingContactInfo(ref)
+	}
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"booking_ref": ref, "contact": info, "masked": masked})
+}
+
+// ProcessPayment handles POST /api/bookings/:ref/payment with
+// {"amount":89.50,"currency":"EUR","card_token":"tok_test_xyz"}. The gateway
+// is a mock: tokens ending in an even digit succeed, the rest are declined
+// with 402.
+func ProcessPayment(c *gin.Context) {
+	var req struct {
+		Amount    float64 `json:"amount" binding:"required"`
+		Currency  string  `json:"currency"`
+		CardToken string  `json:"card_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	payment, err := services.ProcessPayment(c.Param("ref"), req.Amount, req.Currency, req.CardToken)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if payment.Status != "succeeded" {
+		c.JSON(http.StatusPaymentRequired, payment)
+		return
+	}
+	c.JSON(http.StatusOK, payment)
+}
+
+// GetConfirmationPreview handles GET /api/bookings/:ref/confirmation-preview,
+// returning the rendered confirmation email for an authenticated caller.
+func GetConfirmationPreview(c *gin.Context) {
+	html, err := services.GetBookingConfirmationHTML(c.Param("ref"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+}
+
+// GetBookingBarcode handles GET /api/bookings/:ref/barcode.png, serving a
+// scannable Code128 barcode of the booking reference.
+func GetBookingBarcode(c *gin.Context) {
+	img, err := services.GenerateBookingBarcode(c.Param("ref"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.Header("Cache-Control", "max-age=3600")
+	c.Data(http.StatusOK, "image/png", img)
+}
+
+// GetBookingCalendar handles GET /api/bookings/:ref/calendar.ics, serving
+// the journey as an iCalendar event passengers can import.
+func GetBookingCalendar(c *gin.Context) {
+	ics, err := services.ExportBookingToICS(c.Param("ref"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+c.Param("ref")+`.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
+
+// CheckPartialRefund handles GET /api/bookings/:ref/partial-refund-check.
+func CheckPartialRefund(c *gin.Context) {
+	check, err := services.CheckPartialRefundEligibility(c.Param("ref"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, check)
+}
+
+// ListPassengerTypes handles GET /api/passenger-types, returning the
+// canonical passenger type definitions for front-end dropdowns.
+func ListPassengerTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, services.GetAllPassengerTypes())
+}
+
+// GetModificationWindow handles GET /api/bookings/:ref/modification-window,
+// reporting until when the booking can still be cancelled or changed.
+func GetModificationWindow(c *gin.Context) {
+	window, err := services.GetBookingModificationWindow(c.Param("ref"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, window)
+}
+
+// RemoveBookingPassenger handles DELETE /api/bookings/:ref/passengers/:passenger_id,
+// refunding one passenger's fare and recalculating the booking total.
+func RemoveBookingPassenger(c *gin.Context) {
+	passengerID, err := strconv.Atoi(c.Param("passenger_id"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, errors.New("invalid passenger id"))
+		return
+	}
+
+	booking, err := services.RemovePassenger(c.Param("ref"), passengerID)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
+
+// ChangeBookingDate handles PUT /api/bookings/:ref/date.
+func ChangeBookingDate(c *gin.Context) {
+	var req struct {
+		NewDate string `json:"new_date" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	booking, err := services.ChangeBookingDate(c.Param("ref"), req.NewDate)
+	if errors.Is(err, services.ErrModificationLimit) {
+		errorResponse(c, http.StatusForbidden, err)
+		return
+	}
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
+
+// UpdatePassengers handles PUT /api/bookings/:ref/passengers.
+func UpdatePassengers(c *gin.Context) {
+	var req struct {
+		Passengers []models.PassengerCreateRequest `json:"passengers" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	booking, err := services.UpdatePassengers(c.Param("ref"), req.Passengers)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
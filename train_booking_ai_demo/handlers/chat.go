@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/models"
+	"trainbooking/services"
+)
+
+// Chat handles POST /api/ai/chat: one turn of the booking assistant.
+func Chat(c *gin.Context) {
+	var req models.ChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := services.ProcessMessage(c.Request.Context(), req.SessionID, req.Message, req.Metadata)
+	if err != nil {
+		errorResponse(c, http.StatusBadGateway, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/models"
+	"trainbooking/services"
+)
+
+// maxDocumentSize caps uploaded ID document scans at 5 MB.
+const maxDocumentSize = 5 << 20
+
+// documentExtensions are the accepted ID document formats.
+var documentExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".pdf": true,
+}
+
+// CreateBookingWithDocuments handles POST /api/bookings/with-documents.
+// It accepts multipart/form-data with a booking_data JSON field and one
+// document_{n} file per passenger, stored under cfg.DocumentStoragePath.
+func CreateBookingWithDocuments(storagePath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.PostForm("booking_data")
+		if raw == "" {
+			errorResponse(c, http.StatusBadRequest, fmt.Errorf("booking_data field is required"))
+			return
+		}
+		var req models.BookingRequest
+		if err := json.Unmarshal([]byte(raw), &req); err != nil {
+			errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid booking_data: %v", err))
+			return
+		}
+
+		files := make([]*multipart.FileHeader, len(req.Passengers))
+		for i := range req.Passengers {
+			file, err := c.FormFile(fmt.Sprintf("document_%d", i))
+			if err != nil {
+				errorResponse(c, http.StatusBadRequest,
+					fmt.Errorf("document_%d is required (one per passenger)", i))
+				return
+			}
+			if err := validateDocument(file); err != nil {
+				errorResponse(c, http.StatusBadRequest, err)
+				return
+			}
+			files[i] = file
+		}
+
+		booking, err := services.CreateBooking(req)
+		if err != nil {
+			errorResponse(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		if err := os.MkdirAll(storagePath, 0o755); err != nil {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+		paths := make([]string, len(files))
+		for i, file := range files {
+			ext := strings.ToLower(filepath.Ext(file.Filename))
+			paths[i] = filepath.Join(storagePath,
+				fmt.Sprintf("%s-%d%s", booking.BookingRef, i, ext))
+			if err := c.SaveUploadedFile(file, paths[i]); err != nil {
+				errorResponse(c, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		if err := services.AttachBookingDocuments(booking.BookingRef, paths); err != nil {
+			errorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, booking)
+	}
+}
+
+// GetBookingDocument handles GET /api/bookings/:ref/documents/:index.
+func GetBookingDocument(c *gin.Context) {
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid document index"))
+		return
+	}
+	path, err := services.GetBookingDocumentPath(c.Param("ref"), index)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.FileAttachment(path, filepath.Base(path))
+}
+
+func validateDocument(file *multipart.FileHeader) error {
+	if file.Size > maxDocumentSize {
+		return fmt.Errorf("%s exceeds the 5 MB document limit", file.Filename)
+	}
+	if !documentExtensions[strings.ToLower(filepath.Ext(file.Filename))] {
+		return fmt.Errorf("%s: documents must be JPEG, PNG or PDF", file.Filename)
+	}
+	return nil
+}
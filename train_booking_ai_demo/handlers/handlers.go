@@ -0,0 +1,29 @@
+// Package handlers contains the Gin HTTP handlers. They validate input,
+// delegate to the services package and shape the JSON responses.
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"trainbooking/services"
+)
+
+// The passenger_type binding tag validates against the passenger type
+// definitions in services, so adding a tier never means touching struct
+// tags.
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := v.RegisterValidation("passenger_type", func(fl validator.FieldLevel) bool {
+			return services.IsValidPassengerType(fl.Field().String())
+		}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// errorResponse renders a uniform error body.
+func errorResponse(c *gin.Context, status int, err error) {
+	c.JSON(status, gin.H{"error": err.Error()})
+}
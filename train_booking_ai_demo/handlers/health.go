@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/config"
+	"trainbooking/database"
+	"trainbooking/services"
+)
+
+// healthComponent is one row of the health report: a named subsystem and its
+// current status.
+type healthComponent struct {
+	Name   string
+	Status string
+	Detail string
+	OK     bool
+}
+
+// healthSnapshot runs the health checks shared by the JSON endpoint and the
+// HTML dashboard.
+func healthSnapshot() (components []healthComponent, healthy bool) {
+	healthy = true
+
+	components = append(components, healthComponent{
+		Name: "server", Status: "ok", OK: true,
+	})
+
+	dbComponent := healthComponent{Name: "database", Status: "ok", OK: true}
+	if err := database.DB.Ping(); err != nil {
+		dbComponent.Status = "error"
+		dbComponent.Detail = err.Error()
+		dbComponent.OK = false
+		healthy = false
+	}
+	components = append(components, dbComponent)
+
+	provider := services.ActiveAIProvider()
+	breaker := services.CircuitBreakerState(provider)
+	components = append(components, healthComponent{
+		Name:   "ai_provider",
+		Status: breaker,
+		Detail: provider,
+		OK:     breaker != "open",
+	})
+	return components, healthy
+}
+
+// Health handles GET /health, reporting the server, database and configured
+// AI provider status.
+func Health(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		components, healthy := healthSnapshot()
+
+		status := http.StatusOK
+		overall := "ok"
+		if !healthy {
+			status = http.StatusServiceUnavailable
+			overall = "degraded"
+		}
+
+		dbStatus := components[1].Status
+		if components[1].Detail != "" {
+			dbStatus += ": " + components[1].Detail
+		}
+		c.JSON(status, gin.H{
+			"status":    overall,
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"database":  dbStatus,
+			"ai_provider": gin.H{
+				"name":            components[2].Detail,
+				"circuit_breaker": components[2].Status,
+			},
+		})
+	}
+}
+
+//go:embed templates/health.html
+var healthTemplateFS embed.FS
+
+var healthTemplate = template.Must(template.ParseFS(healthTemplateFS, "templates/health.html"))
+
+// ServeHealthUI handles GET /health/ui: the same checks as /health rendered
+// as a self-refreshing HTML dashboard for a wall monitor. No JavaScript,
+// just a meta refresh.
+func ServeHealthUI(cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		components, healthy := healthSnapshot()
+
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		err := healthTemplate.Execute(c.Writer, gin.H{
+			"Components": components,
+			"Healthy":    healthy,
+			"CheckedAt":  time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+		}
+	}
+}
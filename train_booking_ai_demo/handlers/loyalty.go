@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/services"
+)
+
+// GetLoyaltyCard handles GET /api/loyalty/:card_number, returning the card's
+// points balance and tier.
+func GetLoyaltyCard(c *gin.Context) {
+	card, err := services.GetLoyaltyCard(c.Param("card_number"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, card)
+}
+
+// GetLoyaltyCardBookings handles
+// GET /api/loyalty/:card_number/bookings?page=1&page_size=10.
+func GetLoyaltyCardBookings(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	bookings, err := services.GetBookingsByLoyaltyCard(c.Param("card_number"), page, pageSize)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"bookings":  bookings,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/services"
+)
+
+// GetSchedulesByDay handles GET /api/schedules/by-day/:day, where :day is
+// 0 (Sunday) through 6 (Saturday). It is public so operators and the
+// frontend can show which services run on a given weekday.
+func GetSchedulesByDay(c *gin.Context) {
+	day, err := strconv.Atoi(c.Param("day"))
+	if err != nil || day < 0 || day > 6 {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("day must be 0 (Sunday) through 6 (Saturday)"))
+		return
+	}
+
+	schedules, err := services.GetSchedulesByDayOfWeek(day)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	totalSeats := 0
+	routes := map[[2]int]bool{}
+	for _, s := range schedules {
+		totalSeats += s.AvailableSeats
+		routes[[2]int{s.OriginID, s.DestinationID}] = true
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"summary": gin.H{
+			"day_name":              time.Weekday(day).String(),
+			"total_schedules":       len(schedules),
+			"total_available_seats": totalSeats,
+			"distinct_routes":       len(routes),
+		},
+		"schedules": schedules,
+	})
+}
@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+
+	"trainbooking/models"
+	"trainbooking/services"
+)
+
+// SearchTrains handles POST /api/search.
+func SearchTrains(c *gin.Context) {
+	var req models.SearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	results, err := services.SearchTrains(req)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, searchEnvelope(req, results))
+}
+
+// SearchTrainsGET handles GET /api/search, the bookmarkable twin of the
+// POST endpoint. Parameters come from the query string; filters accepts a
+// JSON-encoded object (filters={"has_wifi":true}). Responses carry an ETag
+// derived from the route, date and current seat availability, so retrying
+// clients sending If-None-Match get a 304 until a seat is booked or freed.
+func SearchTrainsGET(c *gin.Context) {
+	req, err := searchRequestFromQuery(c)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// The seat sum is re-queried on every request — only the response body
+	// is spared, never freshness.
+	if etag, err := searchETag(req); err == nil {
+		c.Header("ETag", etag)
+		if c.GetHeader("If-None-Match") == etag {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	results, err := services.SearchTrains(req)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, searchEnvelope(req, results))
+}
+
+// searchETag derives the search response ETag from the request and the
+// route's current total of available seats.
+func searchETag(req models.SearchRequest) (string, error) {
+	sum, err := services.SearchAvailableSeatsSum(req.Origin, req.Destination, req.Date)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%d", req.Origin, req.Destination, req.Date, sum)))
+	return fmt.Sprintf(`"%x"`, digest), nil
+}
+
+// searchEnvelope wraps search results with a route-level estimated duration
+// hint. The estimate is best effort and omitted when unavailable.
+func searchEnvelope(req models.SearchRequest, results []models.SearchResponse) gin.H {
+	envelope := gin.H{"results": results}
+	if estimate, err := services.EstimateTravelTime(req.Origin, req.Destination); err == nil {
+		envelope["estimated_duration"] = services.FormatTravelTime(estimate)
+	}
+	return envelope
+}
+
+// SearchTrainsByArrival handles POST /api/search/by-arrival with
+// {"destination":"RM","arrival_before":"10:00","date":"2025-10-20"}, for
+// users who know when they need to arrive. origin is optional.
+func SearchTrainsByArrival(c *gin.Context) {
+	var req struct {
+		Origin         string `json:"origin"`
+		Destination    string `json:"destination" binding:"required"`
+		ArrivalBefore  string `json:"arrival_before" binding:"required"`
+		Date           string `json:"date" binding:"required"`
+		PassengerCount int    `json:"passenger_count"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	results, err := services.SearchTrainsByArrival(
+		req.Origin, req.Destination, req.ArrivalBefore, req.Date, req.PassengerCount)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// GetRouteMatrix handles POST /api/search/route-matrix with
+// {"station_codes":["MI","RM","NA"]}, returning an N×N grid of the cheapest
+// direct option between every ordered station pair.
+func GetRouteMatrix(c *gin.Context) {
+	var req struct {
+		StationCodes []string `json:"station_codes" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	matrix, err := services.GetRouteMatrix(req.StationCodes)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"stations": req.StationCodes, "matrix": matrix})
+}
+
+// GetNextDeparture handles
+// GET /api/search/next-departure?origin=MI&destination=RM&from=2025-10-20T09:30:00Z.
+// Without from it starts from the current time.
+func GetNextDeparture(c *gin.Context) {
+	origin, destination := c.Query("origin"), c.Query("destination")
+	if origin == "" || destination == "" {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("origin and destination are required"))
+		return
+	}
+	from := time.Now()
+	if s := c.Query("from"); s != "" {
+		parsed, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid from %q: use RFC 3339", s))
+			return
+		}
+		from = parsed
+	}
+
+	result, err := services.FindNextAvailableDeparture(origin, destination, from)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// EstimateTravelTime handles GET /api/search/estimate-time?origin=MI&destination=RM.
+func EstimateTravelTime(c *gin.Context) {
+	origin, destination := c.Query("origin"), c.Query("destination")
+	if origin == "" || destination == "" {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("origin and destination are required"))
+		return
+	}
+
+	estimate, err := services.EstimateTravelTime(origin, destination)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"origin":             origin,
+		"destination":        destination,
+		"estimated_duration": services.FormatTravelTime(estimate),
+	})
+}
+
+// GetRouteDistance handles GET /api/routes/distance?origin=MI&destination=RM.
+func GetRouteDistance(c *gin.Context) {
+	origin, destination := c.Query("origin"), c.Query("destination")
+	if origin == "" || destination == "" {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("origin and destination are required"))
+		return
+	}
+
+	distance, err := services.ComputeRouteDistance(origin, destination)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"origin":      origin,
+		"destination": destination,
+		"distance_km": distance,
+	})
+}
+
+func searchRequestFromQuery(c *gin.Context) (models.SearchRequest, error) {
+	req := models.SearchRequest{
+		Origin:         c.Query("origin"),
+		Destination:    c.Query("destination"),
+		Date:           c.Query("date"),
+		TimePreference: c.Query("time"),
+		SortBy:         c.Query("sort_by"),
+	}
+	if req.Origin == "" || req.Destination == "" || req.Date == "" {
+		return req, fmt.Errorf("origin, destination and date are required")
+	}
+
+	req.PassengerCount, _ = strconv.Atoi(c.DefaultQuery("passengers", "1"))
+
+	if filters := c.Query("filters"); filters != "" {
+		if err := json.Unmarshal([]byte(filters), &req.Filters); err != nil {
+			return req, fmt.Errorf("invalid filters: %v", err)
+		}
+	}
+	return req, nil
+}
+
+// CheckAvailability handles GET /api/availability?origin=MI&destination=RM&date=2025-10-20.
+// It answers with a summary instead of the full result list.
+func CheckAvailability(c *gin.Context) {
+	origin, destination, date := c.Query("origin"), c.Query("destination"), c.Query("date")
+	if origin == "" || destination == "" || date == "" {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("origin, destination and date are required"))
+		return
+	}
+
+	summary, err := services.CheckAvailability(origin, destination, date)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// maxBatchRoutes caps how many routes one batch search may carry.
+const maxBatchRoutes = 10
+
+// batchSearchConcurrency bounds how many searches run at once per request.
+const batchSearchConcurrency = 5
+
+// BatchSearchTrains handles POST /api/search/batch, running up to 10
+// searches concurrently. Individual failures do not fail the batch: each
+// result carries either trains or an error, and the response is 207.
+func BatchSearchTrains(c *gin.Context) {
+	var req struct {
+		Routes []models.SearchRequest `json:"routes" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.Routes) > maxBatchRoutes {
+		errorResponse(c, http.StatusBadRequest,
+			fmt.Errorf("at most %d routes per batch", maxBatchRoutes))
+		return
+	}
+
+	type routeResult struct {
+		RouteIndex int                     `json:"route_index"`
+		Trains     []models.SearchResponse `json:"trains,omitempty"`
+		Error      string                  `json:"error,omitempty"`
+	}
+	results := make([]routeResult, len(req.Routes))
+
+	var g errgroup.Group
+	g.SetLimit(batchSearchConcurrency)
+	for i, route := range req.Routes {
+		i, route := i, route
+		g.Go(func() error {
+			trains, err := services.SearchTrains(route)
+			results[i] = routeResult{RouteIndex: i, Trains: trains}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	c.JSON(http.StatusMultiStatus, gin.H{"results": results})
+}
+
+// maxSeatChecks caps one availability-batch call.
+const maxSeatChecks = 100
+
+// CheckSeatAvailabilityBatch handles POST /api/search/availability-batch,
+// resolving up to 100 (schedule, date) pairs for the calendar view.
+func CheckSeatAvailabilityBatch(c *gin.Context) {
+	var req struct {
+		Requests []services.SeatCheckRequest `json:"requests" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.Requests) > maxSeatChecks {
+		errorResponse(c, http.StatusBadRequest,
+			fmt.Errorf("at most %d items per batch", maxSeatChecks))
+		return
+	}
+
+	results, err := services.CheckSeatAvailabilityBatch(req.Requests)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// SearchMultiLeg handles POST /api/search/multi-leg.
+func SearchMultiLeg(c *gin.Context) {
+	var req models.SearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	journeys, err := services.SearchMultiLeg(req)
+	if err != nil {
+		errorResponse(c, http.StatusUnprocessableEntity, err)
+		return
+	}
+	c.JSON(http.StatusOK, journeys)
+}
+
+// ListStations handles GET /api/stations.
+func ListStations(c *gin.Context) {
+	stations, err := services.GetAllStations()
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, stations)
+}
+
+// GetStation handles GET /api/stations/:code.
+func GetStation(c *gin.Context) {
+	station, err := services.GetStationByCode(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, station)
+}
+
+// GetNearbyStations handles GET /api/stations/:code/nearby?radius_km=50.
+func GetNearbyStations(c *gin.Context) {
+	radius, err := strconv.ParseFloat(c.DefaultQuery("radius_km", "50"), 64)
+	if err != nil || radius <= 0 {
+		errorResponse(c, http.StatusBadRequest, fmt.Errorf("invalid radius_km"))
+		return
+	}
+
+	nearby, err := services.GetNearbyStations(c.Param("code"), radius)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"station":   c.Param("code"),
+		"radius_km": radius,
+		"stations":  nearby,
+	})
+}
+
+// GetLiveDepartures handles GET /api/stations/:code/live-departures?window=60.
+func GetLiveDepartures(c *gin.Context) {
+	window, _ := strconv.Atoi(c.DefaultQuery("window", "60"))
+	departures, err := services.GetLiveDepartures(c.Param("code"), window)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"station":    c.Param("code"),
+		"window":     window,
+		"departures": departures,
+	})
+}
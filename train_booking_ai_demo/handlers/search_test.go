@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+
+	"trainbooking/config"
+	"trainbooking/services"
+)
+
+// TestSearchGETMatchesPOST verifies that the GET and POST search endpoints
+// return identical bodies for equivalent inputs. Requires a seeded database
+// via TEST_DATABASE_URL; skipped otherwise.
+func TestSearchGETMatchesPOST(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set; skipping integration test")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	defer db.Close()
+	services.Init(db, config.Load())
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/api/search", SearchTrains)
+	r.GET("/api/search", SearchTrainsGET)
+
+	post := httptest.NewRecorder()
+	postBody := `{"origin":"MI","destination":"RM","date":"2025-10-20","passenger_count":2}`
+	r.ServeHTTP(post, httptest.NewRequest("POST", "/api/search", strings.NewReader(postBody)))
+
+	get := httptest.NewRecorder()
+	r.ServeHTTP(get, httptest.NewRequest("GET", "/api/search?origin=MI&destination=RM&date=2025-10-20&passengers=2", nil))
+
+	if post.Code != http.StatusOK || get.Code != http.StatusOK {
+		t.Fatalf("status: POST=%d GET=%d, want both 200", post.Code, get.Code)
+	}
+	if post.Body.String() != get.Body.String() {
+		t.Errorf("GET and POST bodies differ:\nPOST: %s\nGET:  %s", post.Body.String(), get.Body.String())
+	}
+}
+
+// TestSearchGETNotModified verifies that repeating an identical GET search
+// with the previous response's ETag yields a 304 without a body. Requires a
+// seeded database via TEST_DATABASE_URL; skipped otherwise.
+func TestSearchGETNotModified(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set; skipping integration test")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	defer db.Close()
+	services.Init(db, config.Load())
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/search", SearchTrainsGET)
+
+	const url = "/api/search?origin=MI&destination=RM&date=2025-10-20"
+	first := httptest.NewRecorder()
+	r.ServeHTTP(first, httptest.NewRequest("GET", url, nil))
+	if first.Code != http.StatusOK {
+		t.Fatalf("first request: status %d, want 200", first.Code)
+	}
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("first response has no ETag")
+	}
+
+	second := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", url, nil)
+	req.Header.Set("If-None-Match", etag)
+	r.ServeHTTP(second, req)
+	if second.Code != http.StatusNotModified {
+		t.Fatalf("repeated request: status %d, want 304", second.Code)
+	}
+	if second.Body.Len() != 0 {
+		t.Errorf("304 response has a body: %s", second.Body.String())
+	}
+}
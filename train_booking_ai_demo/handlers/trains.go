@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/services"
+)
+
+// ListTrains handles GET /api/trains?type=FR&has_wifi=true. It is public so
+// the frontend can populate the train type dropdown without hardcoding.
+func ListTrains(c *gin.Context) {
+	filter := services.TrainFilter{Type: c.Query("type")}
+
+	var err error
+	if filter.HasWifi, err = boolQuery(c, "has_wifi"); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+	if filter.HasFood, err = boolQuery(c, "has_food"); err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	trains, err := services.ListTrains(filter)
+	if err != nil {
+		errorResponse(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, trains)
+}
+
+// GetTrainDelayStats handles GET /api/trains/:number/delay-stats?months=6.
+func GetTrainDelayStats(c *gin.Context) {
+	months, _ := strconv.Atoi(c.DefaultQuery("months", "6"))
+
+	stats, err := services.GetTrainDelayStatistics(c.Param("number"), months)
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// ListTrainTypes handles GET /api/train-types. Public: the frontend renders
+// the fleet description from this instead of hardcoding it.
+func ListTrainTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, services.GetTrainTypes())
+}
+
+// GetTrainTypeInfo handles GET /api/train-types/:code.
+func GetTrainTypeInfo(c *gin.Context) {
+	info, err := services.GetTrainType(c.Param("code"))
+	if err != nil {
+		errorResponse(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, info)
+}
+
+// boolQuery parses an optional boolean query parameter, nil when absent.
+func boolQuery(c *gin.Context, key string) (*bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q", key, raw)
+	}
+	return &value, nil
+}
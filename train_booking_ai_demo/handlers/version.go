@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/version"
+)
+
+// GetAPIVersion handles GET /api/version, reporting the build metadata baked
+// in at link time plus the process uptime. Unauthenticated, so deploy
+// tooling can check which version an instance runs.
+func GetAPIVersion(startTime time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"version":        version.Version,
+			"commit":         version.Commit,
+			"build_date":     version.BuildDate,
+			"go_version":     runtime.Version(),
+			"uptime_seconds": int64(time.Since(startTime).Seconds()),
+		})
+	}
+}
@@ -0,0 +1,227 @@
+/*
+AI-assisted train booking demo: a REST API plus an LLM chat assistant
+(OpenAI, Anthropic or Ollama) that can search and book trains through
+function calling. See the README for setup.
+*/
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/config"
+	"trainbooking/database"
+	"trainbooking/handlers"
+	"trainbooking/middleware"
+	"trainbooking/services"
+)
+
+// startTime is set in main and feeds the uptime reported by /api/version.
+var startTime time.Time
+
+func main() {
+	startTime = time.Now()
+
+	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+
+	if err := database.Connect(cfg); err != nil {
+		log.Fatalf("database connection failed: %v", err)
+	}
+	if err := database.RunMigrations(); err != nil {
+		log.Fatalf("migrations failed: %v", err)
+	}
+	if err := database.EnsurePgTrgmExtension(database.DB); err != nil {
+		// Non-fatal: station matching falls back to ILIKE without pg_trgm.
+		log.Printf("warning: %v", err)
+	}
+
+	services.Init(database.DB, cfg)
+	middleware.SetJWTSecret(cfg.JWTSecret)
+	middleware.SetBlocklistCheck(services.IsTokenBlocked)
+
+	go func() {
+		for range time.Tick(time.Hour) {
+			if n, err := services.PruneExpiredBlocklistEntries(); err != nil {
+				log.Printf("pruning token blocklist: %v", err)
+			} else if n > 0 {
+				log.Printf("pruned %d expired blocklist entries", n)
+			}
+		}
+	}()
+
+	// Nightly overbooking sweep: recount sold seats against capacity so race
+	// damage is spotted before departure day.
+	go func() {
+		for {
+			next := time.Now().Truncate(24 * time.Hour).Add(24 * time.Hour)
+			time.Sleep(time.Until(next))
+			reports, err := services.FindOverbookedSchedules()
+			if err != nil {
+				log.Printf("overbooking sweep: %v", err)
+				continue
+			}
+			for _, r := range reports {
+				log.Printf("OVERBOOKED: schedule %d on %s has %d confirmed passengers for %d seats (+%d)",
+					r.ScheduleID, r.Date, r.ConfirmedPassengers, r.TotalSeats, r.Discrepancy)
+			}
+		}
+	}()
+
+	r := setupRouter(cfg)
+	log.Printf("listening on :%s (AI provider: %s)", cfg.Port, cfg.AIProvider)
+	if err := r.Run(":" + cfg.Port); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func setupRouter(cfg *config.Config) *gin.Engine {
+	r := gin.Default()
+
+	r.Use(middleware.SecurityHeaders())
+	r.Use(cors.New(cors.Config{
+		AllowOrigins:     cfg.CORSAllowedOrigins,
+		AllowMethods:     cfg.CORSAllowedMethods,
+		AllowHeaders:     cfg.CORSAllowedHeaders,
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           time.Duration(cfg.CORSMaxAgeHours) * time.Hour,
+	}))
+	routeTimeouts, err := middleware.ParseRouteTimeouts(cfg.RouteTimeouts, 45*time.Second)
+	if err != nil {
+		log.Fatalf("invalid ROUTE_TIMEOUTS: %v", err)
+	}
+	r.Use(routeTimeouts.Middleware())
+	r.Use(middleware.MaintenanceMode())
+
+	r.GET("/health", handlers.Health(cfg))
+	r.GET("/health/ui", handlers.ServeHealthUI(cfg))
+
+	api := r.Group("/api")
+	{
+		api.GET("/version", handlers.GetAPIVersion(startTime))
+
+		api.POST("/auth/login", handlers.Login)
+		api.POST("/auth/logout", middleware.Auth(), handlers.Logout)
+
+		api.GET("/stations", handlers.ListStations)
+		api.GET("/stations/:code", handlers.GetStation)
+		api.GET("/stations/:code/live-departures", handlers.GetLiveDepartures)
+		api.GET("/stations/:code/nearby", handlers.GetNearbyStations)
+
+		api.GET("/trains", handlers.ListTrains)
+		api.GET("/trains/:number/delay-stats", handlers.GetTrainDelayStats)
+		api.GET("/train-types", handlers.ListTrainTypes)
+		api.GET("/passenger-types", handlers.ListPassengerTypes)
+		api.GET("/train-types/:code", handlers.GetTrainTypeInfo)
+
+		api.GET("/schedules/by-day/:day", handlers.GetSchedulesByDay)
+
+		api.POST("/search", handlers.SearchTrains)
+		api.GET("/search", handlers.SearchTrainsGET)
+		api.POST("/search/by-arrival", handlers.SearchTrainsByArrival)
+		api.POST("/search/route-matrix", handlers.GetRouteMatrix)
+		api.POST("/search/multi-leg", handlers.SearchMultiLeg)
+		api.POST("/search/batch", handlers.BatchSearchTrains)
+		api.POST("/search/availability-batch", handlers.CheckSeatAvailabilityBatch)
+		api.GET("/availability", handlers.CheckAvailability)
+		api.GET("/search/estimate-time", handlers.EstimateTravelTime)
+		api.GET("/search/next-departure", handlers.GetNextDeparture)
+
+		api.GET("/routes/distance", handlers.GetRouteDistance)
+
+		api.POST("/bookings", handlers.CreateBooking)
+		api.POST("/bookings/with-documents", handlers.CreateBookingWithDocuments(cfg.DocumentStoragePath))
+		api.GET("/bookings/recent", handlers.GetRecentBookings)
+		api.GET("/bookings/verify",
+			middleware.RateLimit(5, time.Minute), handlers.VerifyBooking)
+		api.GET("/bookings/:ref", handlers.GetBooking)
+		api.GET("/bookings/:ref/documents/:index", handlers.GetBookingDocument)
+		api.DELETE("/bookings/:ref", handlers.CancelBooking)
+		api.POST("/bookings/:ref/duplicate", handlers.DuplicateBooking)
+		api.POST("/bookings/:ref/payment", handlers.ProcessPayment)
+		api.GET("/bookings/:ref/contact",
+			middleware.OptionalAuth(), handlers.GetBookingContact)
+		api.PUT("/bookings/:ref/date", handlers.ChangeBookingDate)
+		api.PUT("/bookings/:ref/passengers", handlers.UpdatePassengers)
+		api.GET("/bookings/:ref/partial-refund-check", handlers.CheckPartialRefund)
+		api.GET("/bookings/:ref/modification-window", handlers.GetModificationWindow)
+		api.GET("/bookings/:ref/calendar.ics", handlers.GetBookingCalendar)
+		api.GET("/bookings/:ref/barcode.png", handlers.GetBookingBarcode)
+		api.GET("/bookings/:ref/confirmation-preview",
+			middleware.Auth(), handlers.GetConfirmationPreview)
+		api.DELETE("/bookings/:ref/passengers/:passenger_id", handlers.RemoveBookingPassenger)
+
+		api.GET("/analytics/peak-hours", handlers.GetPeakHours)
+
+		api.GET("/loyalty/:card_number", handlers.GetLoyaltyCard)
+		api.GET("/loyalty/:card_number/bookings", handlers.GetLoyaltyCardBookings)
+
+		api.POST("/ai/chat", handlers.Chat)
+	}
+
+	adminAllowlist, err := middleware.ParseCIDRs(cfg.AdminIPAllowlist)
+	if err != nil {
+		log.Fatalf("invalid ADMIN_IP_ALLOWLIST: %v", err)
+	}
+
+	admin := api.Group("/admin",
+		middleware.IPAllowlist(adminAllowlist), middleware.Auth(), middleware.AdminOnly())
+	{
+		admin.GET("/schedules/:id/manifest", handlers.GetScheduleManifest)
+		admin.POST("/schedules", handlers.CreateSchedule)
+		admin.PUT("/schedules/:id", handlers.UpdateSchedule)
+		admin.PATCH("/schedules/:id/pricing", handlers.UpdateSchedulePricing)
+		admin.DELETE("/schedules/:id", handlers.DeleteSchedule)
+		admin.POST("/schedules/:id/copy-to-train", handlers.CopyScheduleToTrain)
+		admin.POST("/schedules/bulk-price-update", handlers.BulkPriceUpdate)
+		admin.GET("/schedules/:id/revenue", handlers.GetScheduleRevenue)
+		admin.GET("/schedules/:id/load-factor", handlers.GetScheduleLoadFactor)
+		admin.GET("/schedules/:id/price-history", handlers.GetSchedulePriceHistory)
+		admin.POST("/schedules/:id/exceptions", handlers.AddScheduleException)
+		admin.DELETE("/schedules/:id/exceptions/:date", handlers.DeleteScheduleException)
+		admin.GET("/schedules/:id/overbooking-check", handlers.CheckOverbooking)
+		admin.GET("/schedules/:id/group-report", handlers.GetGroupBookingReport)
+		admin.POST("/schedules/:id/recalculate-seats", handlers.RecalculateSeats)
+		admin.POST("/ai/switch-provider", handlers.SwitchAIProvider)
+		admin.POST("/ai/replay", handlers.ReplayConversation)
+		admin.POST("/ai/batch-response-test", handlers.RunAIBatchTest)
+		admin.POST("/maintenance-mode", handlers.SetMaintenanceMode)
+		admin.DELETE("/sessions/:id", handlers.DeleteSession)
+		admin.GET("/bookings/export", handlers.ExportBookings)
+		admin.GET("/bookings/high-value", handlers.GetHighValueBookings)
+		admin.GET("/bookings/:ref/price-check", handlers.CheckBookingPrice)
+		admin.GET("/bookings/:ref/cancellation-risk", handlers.GetCancellationRisk)
+		admin.POST("/trains/:id/out-of-service", handlers.SetTrainOutOfService)
+		admin.DELETE("/trains/:id/out-of-service", handlers.ClearTrainOutOfService)
+		admin.GET("/passengers/history", handlers.GetPassengerHistory)
+		admin.GET("/conversations/search", handlers.SearchConversations)
+		admin.POST("/conversations/purge-abandoned", handlers.PurgeAbandonedConversations)
+		admin.POST("/notifications/broadcast", handlers.BroadcastNotification)
+		admin.GET("/heatmap", handlers.GetOccupancyHeatmap)
+		admin.GET("/capacity-report", handlers.GetCapacityReport)
+		admin.GET("/analytics/frequent-travellers", handlers.GetFrequentTravellers)
+		admin.GET("/analytics/train-types", handlers.GetTrainTypeStats)
+		admin.GET("/analytics/function-calls", handlers.GetAIFunctionCallStats)
+		admin.GET("/analytics/conversation-flow", handlers.GetConversationFlowStats)
+		admin.GET("/analytics/bookings-by-day", handlers.GetBookingsByDay)
+		admin.GET("/analytics/bookings-by-day/summary", handlers.GetBookingsByDaySummary)
+		admin.POST("/db/query", handlers.RunDatabaseQuery)
+		admin.GET("/db/stats", handlers.GetDatabaseStats)
+		admin.POST("/stress-test/bookings", handlers.StressTestBookings(cfg.EnableStressTest))
+		admin.DELETE("/test-data", handlers.PurgeTestData(cfg.AllowTestDataPurge))
+		admin.POST("/alerts", handlers.CreateAlert)
+		admin.GET("/alerts", handlers.ListScheduleAlerts)
+		admin.PUT("/alerts/:id", handlers.UpdateAlert)
+		admin.DELETE("/alerts/:id", handlers.DeleteAlert)
+		admin.POST("/stations/reindex", handlers.ReindexStations)
+		admin.GET("/stations/:code/report", handlers.GetStationReport)
+		admin.POST("/gdpr/anonymize", handlers.AnonymizeSessions)
+	}
+
+	return r
+}
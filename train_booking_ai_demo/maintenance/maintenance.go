@@ -0,0 +1,34 @@
+// Package maintenance holds the in-memory maintenance-mode flag. The state
+// is process-local on purpose: a restart clears it, which is the safe
+// default after a maintenance window.
+package maintenance
+
+import "sync/atomic"
+
+type state struct {
+	message      string
+	estimatedEnd string
+}
+
+var (
+	enabled atomic.Bool
+	current atomic.Value // state
+)
+
+// SetMode turns maintenance mode on or off. message and estimatedEnd are
+// returned verbatim to blocked clients.
+func SetMode(on bool, message, estimatedEnd string) {
+	current.Store(state{message: message, estimatedEnd: estimatedEnd})
+	enabled.Store(on)
+}
+
+// Enabled reports whether maintenance mode is active.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Status returns the current message and estimated end, empty when never set.
+func Status() (message, estimatedEnd string) {
+	s, _ := current.Load().(state)
+	return s.message, s.estimatedEnd
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TestRevokedTokenRejected covers the logout flow at the middleware level:
+// a valid token passes Auth, then the same token is rejected with 401 once
+// its jti lands on the blocklist.
+func TestRevokedTokenRejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetJWTSecret("test-secret")
+
+	revoked := map[string]bool{}
+	SetBlocklistCheck(func(jti string) (bool, error) { return revoked[jti], nil })
+	defer SetBlocklistCheck(nil)
+
+	r := gin.New()
+	r.GET("/protected", Auth(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"jti": c.GetString("jti")})
+	})
+
+	token, err := IssueToken(1, "admin", time.Hour)
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+
+	request := func() int {
+		req := httptest.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := request(); code != http.StatusOK {
+		t.Fatalf("before logout: status %d, want 200", code)
+	}
+
+	// Simulate logout: extract the jti and blocklist it.
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		return []byte("test-secret"), nil
+	})
+	if err != nil {
+		t.Fatalf("parsing token: %v", err)
+	}
+	jti, _ := parsed.Claims.(jwt.MapClaims)["jti"].(string)
+	if jti == "" {
+		t.Fatal("issued token carries no jti claim")
+	}
+	revoked[jti] = true
+
+	if code := request(); code != http.StatusUnauthorized {
+		t.Fatalf("after logout: status %d, want 401", code)
+	}
+}
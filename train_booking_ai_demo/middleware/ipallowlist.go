@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ParseCIDRs parses a comma-separated list of CIDR ranges, e.g.
+// "10.0.0.0/8,192.168.0.0/16". An empty string yields an empty list.
+func ParseCIDRs(raw string) ([]net.IPNet, error) {
+	var nets []net.IPNet
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", part, err)
+		}
+		nets = append(nets, *ipNet)
+	}
+	return nets, nil
+}
+
+// IPAllowlist rejects requests whose client IP is outside the given CIDR
+// ranges with 403. An empty allowlist allows everything, so development
+// setups work without configuration.
+func IPAllowlist(allowlist []net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(allowlist) == 0 {
+			c.Next()
+			return
+		}
+		if !ipAllowed(allowlist, c.ClientIP()) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func ipAllowed(allowlist []net.IPNet, clientIP string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, n := range allowlist {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import "testing"
+
+func TestIPAllowlist(t *testing.T) {
+	cases := []struct {
+		name  string
+		cidrs string
+		ip    string
+		want  bool
+	}{
+		{"inside /8", "10.0.0.0/8", "10.42.1.7", true},
+		{"outside /8", "10.0.0.0/8", "11.0.0.1", false},
+		{"second range matches", "10.0.0.0/8,192.168.0.0/16", "192.168.5.10", true},
+		{"exact /32", "203.0.113.9/32", "203.0.113.9", true},
+		{"neighbour of /32", "203.0.113.9/32", "203.0.113.10", false},
+		{"ipv6 range", "2001:db8::/32", "2001:db8::1", true},
+		{"ipv4 against ipv6 range", "2001:db8::/32", "10.0.0.1", false},
+		{"unparseable client ip", "10.0.0.0/8", "not-an-ip", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowlist, err := ParseCIDRs(tc.cidrs)
+			if err != nil {
+				t.Fatalf("ParseCIDRs(%q): %v", tc.cidrs, err)
+			}
+			if got := ipAllowed(allowlist, tc.ip); got != tc.want {
+				t.Errorf("ipAllowed(%q, %q) = %v, want %v", tc.cidrs, tc.ip, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseCIDRsRejectsGarbage(t *testing.T) {
+	if _, err := ParseCIDRs("10.0.0.0/8,bogus"); err == nil {
+		t.Fatal("expected error for invalid CIDR, got nil")
+	}
+}
+
+func TestParseCIDRsEmpty(t *testing.T) {
+	allowlist, err := ParseCIDRs("")
+	if err != nil {
+		t.Fatalf("ParseCIDRs(\"\"): %v", err)
+	}
+	if len(allowlist) != 0 {
+		t.Errorf("expected empty allowlist, got %d entries", len(allowlist))
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"trainbooking/maintenance"
+)
+
+// maintenanceExempt lists the paths that keep working during a maintenance
+// window: health checks, metrics scrapers, version probes and the toggle
+// itself.
+var maintenanceExempt = map[string]bool{
+	"/health":                     true,
+	"/api/admin/maintenance-mode": true,
+	"/metrics":                    true,
+	"/api/version":                true,
+}
+
+// MaintenanceMode returns 503 for every request while maintenance mode is
+// active, except the exempt paths above.
+func MaintenanceMode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !maintenance.Enabled() || maintenanceExempt[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+		message, estimatedEnd := maintenance.Status()
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"maintenance":   true,
+			"message":       message,
+			"estimated_end": estimatedEnd,
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package middleware provides the Gin middleware used by the router.
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtSecret is set once at startup via SetJWTSecret.
+var jwtSecret []byte
+
+// blocklistCheck reports whether a token id has been revoked. It is wired
+// up at startup via SetBlocklistCheck; nil disables the check (tests,
+// deployments without logout).
+var blocklistCheck func(jti string) (bool, error)
+
+// SetBlocklistCheck configures the revocation lookup used by Auth.
+func SetBlocklistCheck(check func(jti string) (bool, error)) {
+	blocklistCheck = check
+}
+
+// SetJWTSecret configures the key used to sign and verify tokens.
+func SetJWTSecret(secret string) {
+	jwtSecret = []byte(secret)
+}
+
+// IssueToken creates a signed JWT for a user. Every token carries a unique
+// jti claim so it can be revoked individually at logout.
+func IssueToken(userID int, role string, ttl time.Duration) (string, error) {
+	jti, err := newJTI()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+		"jti":     jti,
+		"exp":     time.Now().Add(ttl).Unix(),
+	})
+	return token.SignedString(jwtSecret)
+}
+
+// newJTI returns a random UUIDv4 string.
+func newJTI() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	buf[6] = (buf[6] & 0x0f) | 0x40
+	buf[8] = (buf[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16]), nil
+}
+
+// Auth validates the Bearer token and stores user_id and role in the
+// request context.
+func Auth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+
+		token, err := jwt.Parse(strings.TrimPrefix(header, "Bearer "), func(t *jwt.Token) (interface{}, error) {
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return jwtSecret, nil
+		})
+		if err != nil || !token.Valid {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			return
+		}
+
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+			return
+		}
+		if jti, ok := claims["jti"].(string); ok {
+			if blocklistCheck != nil {
+				blocked, err := blocklistCheck(jti)
+				if err != nil {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "checking token"})
+					return
+				}
+				if blocked {
+					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
+					return
+				}
+			}
+			c.Set("jti", jti)
+		}
+		if exp, ok := claims["exp"].(float64); ok {
+			c.Set("token_exp", int64(exp))
+		}
+		if userID, ok := claims["user_id"].(float64); ok {
+			c.Set("user_id", int(userID))
+		}
+		if role, ok := claims["role"].(string); ok {
+			c.Set("role", role)
+		}
+		c.Next()
+	}
+}
+
+// OptionalAuth stores the claims of a valid Bearer token in the request
+// context like Auth, but lets requests without one (or with a bad one)
+// through anonymously. For endpoints that behave differently for
+// authenticated users without requiring login.
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			c.Next()
+			return
+		}
+
+		token, err := jwt.Parse(strings.TrimPrefix(header, "Bearer "), func(t *jwt.Token) (interface{}, error) {
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return jwtSecret, nil
+		})
+		if err != nil || !token.Valid {
+			c.Next()
+			return
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			c.Next()
+			return
+		}
+		if jti, ok := claims["jti"].(string); ok && blocklistCheck != nil {
+			if blocked, err := blocklistCheck(jti); err != nil || blocked {
+				c.Next()
+				return
+			}
+		}
+		if userID, ok := claims["user_id"].(float64); ok {
+			c.Set("user_id", int(userID))
+		}
+		if role, ok := claims["role"].(string); ok {
+			c.Set("role", role)
+		}
+		c.Next()
+	}
+}
+
+// AdminOnly rejects requests whose token does not carry the admin role.
+// It must run after Auth.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != "admin" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin role required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Timeout attaches a deadline to the request context so downstream HTTP and
+// database calls are cancelled when the budget is exhausted.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
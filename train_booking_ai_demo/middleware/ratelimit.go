@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit returns a per-client-IP sliding-window limiter: at most max
+// requests per window, answering 429 beyond that. State is in-memory, which
+// is enough for a single instance; stale IPs are pruned as they come back.
+func RateLimit(max int, window time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	hits := map[string][]time.Time{}
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		recent := hits[ip][:0]
+		for _, t := range hits[ip] {
+			if now.Sub(t) < window {
+				recent = append(recent, t)
+			}
+		}
+		if len(recent) >= max {
+			hits[ip] = recent
+			mu.Unlock()
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "too many attempts, retry later",
+			})
+			return
+		}
+		hits[ip] = append(recent, now)
+		mu.Unlock()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteTimeouts resolves the request timeout per route. Rules come from the
+// ROUTE_TIMEOUTS env var as JSON mapping "METHOD /path/prefix" patterns to
+// durations; the most specific (longest) matching prefix wins and
+// unmatched routes use the fallback.
+type RouteTimeouts struct {
+	rules    []routeRule
+	fallback time.Duration
+}
+
+type routeRule struct {
+	method  string
+	prefix  string
+	timeout time.Duration
+}
+
+// ParseRouteTimeouts parses `{"POST /api/ai/chat": "30s", "GET /health": "2s"}`.
+func ParseRouteTimeouts(raw string, fallback time.Duration) (*RouteTimeouts, error) {
+	rt := &RouteTimeouts{fallback: fallback}
+	if raw == "" {
+		return rt, nil
+	}
+
+	var entries map[string]string
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("invalid ROUTE_TIMEOUTS JSON: %w", err)
+	}
+	for pattern, value := range entries {
+		parts := strings.Fields(pattern)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid route pattern %q: want \"METHOD /path\"", pattern)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q for %q: %w", value, pattern, err)
+		}
+		rt.rules = append(rt.rules, routeRule{
+			method:  strings.ToUpper(parts[0]),
+			prefix:  parts[1],
+			timeout: d,
+		})
+	}
+
+	// Longest prefix first, so the first match is the most specific one.
+	sort.Slice(rt.rules, func(i, j int) bool {
+		return len(rt.rules[i].prefix) > len(rt.rules[j].prefix)
+	})
+	return rt, nil
+}
+
+// For returns the timeout that applies to a method and path.
+func (rt *RouteTimeouts) For(method, path string) time.Duration {
+	for _, rule := range rt.rules {
+		if rule.method == method && strings.HasPrefix(path, rule.prefix) {
+			return rule.timeout
+		}
+	}
+	return rt.fallback
+}
+
+// Middleware enforces the per-route timeout: the rest of the chain runs
+// under a deadline and a 504 is written if it does not finish in time.
+// Late writes from the handler are discarded.
+func (rt *RouteTimeouts) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := rt.For(c.Request.Method, c.Request.URL.Path)
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		tw := &timeoutWriter{ResponseWriter: c.Writer}
+		c.Writer = tw
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Next()
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			tw.markTimedOut()
+			c.Abort()
+			tw.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+			json.NewEncoder(tw.ResponseWriter).Encode(gin.H{"error": "request timed out"})
+		}
+	}
+}
+
+// timeoutWriter silently drops writes that arrive after the timeout
+// response has been sent.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu       sync.Mutex
+	timedOut bool
+}
+
+func (w *timeoutWriter) markTimedOut() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = true
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseRouteTimeoutsMostSpecificWins(t *testing.T) {
+	rt, err := ParseRouteTimeouts(
+		`{"POST /api/ai/chat": "30s", "POST /api": "10s", "GET /health": "2s"}`,
+		45*time.Second,
+	)
+	if err != nil {
+		t.Fatalf("ParseRouteTimeouts: %v", err)
+	}
+
+	tests := []struct {
+		method, path string
+		want         time.Duration
+	}{
+		{"POST", "/api/ai/chat", 30 * time.Second},
+		{"POST", "/api/bookings", 10 * time.Second},
+		{"GET", "/health", 2 * time.Second},
+		{"GET", "/api/search", 45 * time.Second}, // GET not covered by POST rules
+		{"DELETE", "/other", 45 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := rt.For(tt.method, tt.path); got != tt.want {
+			t.Errorf("For(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseRouteTimeoutsRejectsBadInput(t *testing.T) {
+	for _, raw := range []string{`{"GET": "2s"}`, `{"GET /health": "fast"}`, `not-json`} {
+		if _, err := ParseRouteTimeouts(raw, time.Second); err == nil {
+			t.Errorf("ParseRouteTimeouts(%q) succeeded, want error", raw)
+		}
+	}
+}
+
+func TestRouteTimeoutExpires(t *testing.T) {
+	rt, err := ParseRouteTimeouts(`{"GET /health": "2s"}`, 45*time.Second)
+	if err != nil {
+		t.Fatalf("ParseRouteTimeouts: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(rt.Middleware())
+	r.GET("/health", func(c *gin.Context) {
+		time.Sleep(3 * time.Second)
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusGatewayTimeout)
+	}
+	if elapsed := time.Since(start); elapsed >= 3*time.Second {
+		t.Errorf("request took %v, should have timed out after ~2s", elapsed)
+	}
+}
@@ -0,0 +1,103 @@
+package models
+
+// SearchRequest is the body of POST /api/search.
+type SearchRequest struct {
+	Origin         string `json:"origin" binding:"required"`
+	Destination    string `json:"destination" binding:"required"`
+	Date           string `json:"date" binding:"required"`
+	PassengerCount int    `json:"passenger_count"`
+	// TimePreference is one of "", "morning", "afternoon", "evening".
+	TimePreference string                 `json:"time_preference"`
+	Filters        map[string]interface{} `json:"filters"`
+	SortBy         string                 `json:"sort_by"`
+}
+
+// SearchResponse is one result row returned by services.SearchTrains.
+type SearchResponse struct {
+	ScheduleID     int     `json:"schedule_id"`
+	TrainNumber    string  `json:"train_number"`
+	TrainType      string  `json:"train_type"`
+	Origin         string  `json:"origin"`
+	Destination    string  `json:"destination"`
+	DepartureTime  string  `json:"departure_time"`
+	ArrivalTime    string  `json:"arrival_time"`
+	Duration       string  `json:"duration"`
+	PricePerAdult  float64 `json:"price_per_adult"`
+	AvailableSeats int     `json:"available_seats"`
+	// IsAvailable is computed from AvailableSeats when results are built.
+	IsAvailable bool `json:"is_available"`
+	HasWifi     bool `json:"has_wifi"`
+	HasFood     bool `json:"has_food"`
+	// Alerts carries the advisories active on the travel date, if any.
+	Alerts []ScheduleAlert `json:"alerts,omitempty"`
+	// Date is set when the result's travel date is not implied by the
+	// request, e.g. by the next-departure lookup.
+	Date string `json:"date,omitempty"`
+	// ConnectionCount is the number of train changes this result implies:
+	// always 0 for the direct results SearchTrains returns.
+	ConnectionCount int `json:"connection_count"`
+}
+
+// PassengerCreateRequest is one passenger in a BookingRequest.
+type PassengerCreateRequest struct {
+	Name string `json:"name" binding:"required"`
+	// PassengerType is validated against services.GetAllPassengerTypes via
+	// the passenger_type validator registered by the handlers package.
+	PassengerType string `json:"passenger_type" binding:"omitempty,passenger_type"`
+	Email         string `json:"email"`
+	// LoyaltyCardNumber, when given, must match an existing card; the
+	// passenger then earns points on the booking.
+	LoyaltyCardNumber string `json:"loyalty_card_number"`
+}
+
+// BookingRequest is the body of POST /api/bookings.
+type BookingRequest struct {
+	ScheduleID int                      `json:"schedule_id" binding:"required"`
+	Date       string                   `json:"date" binding:"required"`
+	Passengers []PassengerCreateRequest `json:"passengers" binding:"required,min=1,dive"`
+	PromoCode  string                   `json:"promo_code"`
+	// ContactEmail is where confirmations and notifications for the whole
+	// booking are sent. Optional, but bookings without it are logged.
+	ContactEmail string `json:"contact_email"`
+	// ContactPhone is optional, for agents reaching passengers about
+	// disruptions.
+	ContactPhone string `json:"contact_phone"`
+	// SessionID links the booking to the chat session that created it, so
+	// the assistant can find it again later.
+	SessionID string `json:"session_id"`
+}
+
+// ScheduleCreateRequest is the body of POST/PUT /api/admin/schedules.
+type ScheduleCreateRequest struct {
+	TrainID       int     `json:"train_id" binding:"required"`
+	OriginID      int     `json:"origin_id" binding:"required"`
+	DestinationID int     `json:"destination_id" binding:"required"`
+	DepartureTime string  `json:"departure_time" binding:"required"`
+	ArrivalTime   string  `json:"arrival_time" binding:"required"`
+	DayOfWeek     int     `json:"day_of_week"`
+	PriceBase     float64 `json:"price_base"`
+	TotalSeats    int     `json:"total_seats"`
+}
+
+// LoginRequest is the body of POST /api/auth/login.
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginResponse carries the issued JWT.
+type LoginResponse struct {
+	Token string `json:"token"`
+	Role  string `json:"role"`
+}
+
+// ManifestResponse is the passenger manifest for one schedule on one date,
+// used by conductors on board.
+type ManifestResponse struct {
+	ScheduleID      int       `json:"schedule_id"`
+	Date            string    `json:"date"`
+	TrainNumber     string    `json:"train_number"`
+	Route           string    `json:"route"`
+	Bookings        []Booking `json:"bookings"`
+	TotalPassengers int       `json:"total_passengers"`
+}
@@ -0,0 +1,32 @@
+package models
+
+// ChatRequest is the body of POST /api/ai/chat.
+type ChatRequest struct {
+	SessionID string `json:"session_id" binding:"required"`
+	Message   string `json:"message" binding:"required"`
+	// Metadata carries client-side context (e.g. the route selected in the
+	// search widget) that is injected into the prompt so the AI does not
+	// have to ask again.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ChatResponse is the assistant's reply, including any function call the
+// AI executed while producing it.
+type ChatResponse struct {
+	SessionID    string        `json:"session_id"`
+	Message      string        `json:"message"`
+	FunctionCall *FunctionCall `json:"function_call,omitempty"`
+}
+
+// FunctionCall records one AI function invocation. It is persisted as JSON
+// in the conversation_history.function_call column.
+type FunctionCall struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// ChatMessage is one turn of a conversation as sent to the AI provider.
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
@@ -0,0 +1,128 @@
+package models
+
+import "time"
+
+// Station is a row of the stations table.
+type Station struct {
+	ID        int     `json:"id"`
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	City      string  `json:"city"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	// Timezone is the station's IANA timezone, e.g. "Europe/Rome".
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Train is a row of the trains table. Type is one of FR (Frecciarossa),
+// IC (Intercity) or RG (Regionale).
+type Train struct {
+	ID         int    `json:"id"`
+	Number     string `json:"number"`
+	Type       string `json:"type"`
+	TotalSeats int    `json:"total_seats"`
+	HasWifi    bool   `json:"has_wifi"`
+	HasFood    bool   `json:"has_food"`
+
+	OutOfServiceFrom  *time.Time `json:"out_of_service_from,omitempty"`
+	OutOfServiceUntil *time.Time `json:"out_of_service_until,omitempty"`
+
+	// ActiveSchedulesCount is filled by ListTrains only.
+	ActiveSchedulesCount int `json:"active_schedules_count,omitempty"`
+}
+
+// TrainTypeInfo describes one of the fleet's train types in structured form,
+// for the frontend and for generating the assistant's system prompt.
+type TrainTypeInfo struct {
+	Code             string   `json:"code"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	MaxSpeedKmh      int      `json:"max_speed_kmh"`
+	PricePerKm       float64  `json:"price_per_km"`
+	HasWifi          bool     `json:"has_wifi"`
+	HasFood          bool     `json:"has_food"`
+	TypicalAmenities []string `json:"typical_amenities"`
+}
+
+// Schedule is a recurring weekly service: the same train running the same
+// route on a given day of the week.
+type Schedule struct {
+	ID             int     `json:"id"`
+	TrainID        int     `json:"train_id"`
+	TrainNumber    string  `json:"train_number,omitempty"`
+	TrainType      string  `json:"train_type,omitempty"`
+	OriginID       int     `json:"origin_id"`
+	DestinationID  int     `json:"destination_id"`
+	Origin         string  `json:"origin,omitempty"`
+	Destination    string  `json:"destination,omitempty"`
+	DepartureTime  string  `json:"departure_time"`
+	ArrivalTime    string  `json:"arrival_time"`
+	DayOfWeek      int     `json:"day_of_week"`
+	PriceBase      float64 `json:"price_base"`
+	TotalSeats     int     `json:"total_seats"`
+	AvailableSeats int     `json:"available_seats"`
+	IsActive       bool    `json:"is_active"`
+}
+
+// Passenger is a row of the passengers table, belonging to a booking.
+type Passenger struct {
+	ID            int     `json:"id"`
+	BookingID     int     `json:"-"`
+	Name          string  `json:"name"`
+	PassengerType string  `json:"passenger_type"`
+	SeatNumber    string  `json:"seat_number"`
+	Price         float64 `json:"price"`
+	Email         string  `json:"email,omitempty"`
+	// LoyaltyCardNumber is set when the passenger earned points on this
+	// booking.
+	LoyaltyCardNumber string `json:"loyalty_card_number,omitempty"`
+}
+
+// Booking is a confirmed (or cancelled) reservation on a schedule for a
+// specific travel date.
+type Booking struct {
+	ID             int     `json:"id"`
+	BookingRef     string  `json:"booking_ref"`
+	ScheduleID     int     `json:"schedule_id"`
+	BookingDate    string  `json:"booking_date"`
+	Status         string  `json:"status"`
+	TotalPrice     float64 `json:"total_price"`
+	RefundAmount   float64 `json:"refund_amount,omitempty"`
+	PassengerCount int     `json:"passenger_count"`
+	PromoCode      string  `json:"promo_code,omitempty"`
+	ContactEmail   string  `json:"contact_email,omitempty"`
+	ContactPhone   string  `json:"contact_phone,omitempty"`
+	// ConfirmationCode is a 4-digit PIN for verbal verification. It is
+	// only populated on creation, never on public reads.
+	ConfirmationCode string      `json:"confirmation_code,omitempty"`
+	CreatedAt        time.Time   `json:"created_at"`
+	Passengers       []Passenger `json:"passengers,omitempty"`
+
+	// Denormalised schedule info, populated on reads for convenience.
+	TrainNumber   string `json:"train_number,omitempty"`
+	Origin        string `json:"origin,omitempty"`
+	Destination   string `json:"destination,omitempty"`
+	DepartureTime string `json:"departure_time,omitempty"`
+	ArrivalTime   string `json:"arrival_time,omitempty"`
+}
+
+// User is a row of the users table, used for the admin API.
+type User struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ScheduleAlert is an operational advisory attached to a schedule, e.g.
+// engineering works on part of the route.
+type ScheduleAlert struct {
+	ID              int       `json:"id"`
+	ScheduleID      int       `json:"schedule_id"`
+	Severity        string    `json:"severity"`
+	Message         string    `json:"message"`
+	ValidFrom       time.Time `json:"valid_from"`
+	ValidTo         time.Time `json:"valid_to"`
+	CreatedByUserID int       `json:"created_by_user_id,omitempty"`
+}
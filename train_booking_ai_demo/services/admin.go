@@ -0,0 +1,69 @@
+package services
+
+import (
+	"fmt"
+)
+
+// RecordAdminAction appends a row to the admin audit log. Failures are
+// returned so callers can decide whether the action should be rolled back.
+func RecordAdminAction(adminUserID int, action, target string) error {
+	_, err := db.Exec(
+		`INSERT INTO admin_audit_log (admin_user_id, action, target) VALUES ($1, $2, $3)`,
+		adminUserID, action, target,
+	)
+	if err != nil {
+		return fmt.Errorf("recording admin action: %w", err)
+	}
+	return nil
+}
+
+// PurgeTestData deletes everything integration tests left behind: bookings
+// whose reference starts with prefix, together with their passengers and
+// audit events, plus conversation history of sessions starting with prefix.
+// Returns the total number of rows removed across all four tables.
+func PurgeTestData(prefix string) (int64, error) {
+	if prefix == "" {
+		return 0, fmt.Errorf("refusing to purge with an empty prefix")
+	}
+	pattern := prefix + "%"
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var total int64
+	statements := []string{
+		`DELETE FROM passengers WHERE booking_id IN
+		     (SELECT id FROM bookings WHERE booking_ref LIKE $1)`,
+		`DELETE FROM booking_events WHERE booking_ref LIKE $1`,
+		`DELETE FROM conversation_history WHERE session_id LIKE $1`,
+		`DELETE FROM bookings WHERE booking_ref LIKE $1`,
+	}
+	for _, stmt := range statements {
+		res, err := tx.Exec(stmt, pattern)
+		if err != nil {
+			return 0, fmt.Errorf("purging test data: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, tx.Commit()
+}
+
+// DeleteSession hard-deletes all conversation history of one session and
+// returns the number of rows removed. Used for GDPR erasure requests and
+// abuse cleanup.
+func DeleteSession(sessionID string) (int64, error) {
+	res, err := db.Exec(
+		`DELETE FROM conversation_history WHERE session_id = $1`, sessionID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("deleting session %s: %w", sessionID, err)
+	}
+	return res.RowsAffected()
+}
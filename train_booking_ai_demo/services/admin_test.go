@@ -0,0 +1,44 @@
+package services
+
+import "testing"
+
+func TestDeleteSessionRemovesHistoryAndAudits(t *testing.T) {
+	testDB := setupTestDB(t)
+
+	const sessionID = "TEST-delete-session"
+	for _, msg := range []string{"hello", "hi, where to?"} {
+		if err := SaveConversationMessage(sessionID, "user", msg, nil); err != nil {
+			t.Fatalf("seeding conversation: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		testDB.Exec(`DELETE FROM conversation_history WHERE session_id = $1`, sessionID)
+		testDB.Exec(`DELETE FROM admin_audit_log WHERE target = $1`, sessionID)
+	})
+
+	deleted, err := DeleteSession(sessionID)
+	if err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("deleted = %d, want 2", deleted)
+	}
+
+	var remaining int
+	testDB.QueryRow(`SELECT COUNT(*) FROM conversation_history WHERE session_id = $1`, sessionID).Scan(&remaining)
+	if remaining != 0 {
+		t.Errorf("%d conversation rows remain after delete", remaining)
+	}
+
+	if err := RecordAdminAction(1, "delete_session", sessionID); err != nil {
+		t.Fatalf("RecordAdminAction: %v", err)
+	}
+	var audits int
+	testDB.QueryRow(
+		`SELECT COUNT(*) FROM admin_audit_log WHERE action = 'delete_session' AND target = $1`,
+		sessionID,
+	).Scan(&audits)
+	if audits != 1 {
+		t.Errorf("audit rows = %d, want 1", audits)
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"trainbooking/models"
+)
+
+// historyLimit is the number of conversation turns sent to the AI provider.
+const historyLimit = 20
+
+// ProcessMessage runs one turn of the booking assistant: it stores the user
+// message, builds the prompt from the session history and dispatches to the
+// configured AI provider. Function calls requested by the AI are executed
+// and their results fed back before the final reply is returned. Cancelling
+// ctx (e.g. when the HTTP client disconnects) aborts in-flight provider
+// calls. metadata is optional client-side context (see ChatRequest.Metadata);
+// it is sanitised, stored with the user turn and injected into the prompt.
+func ProcessMessage(ctx context.Context, sessionID, userMessage string, metadata map[string]string) (*models.ChatResponse, error) {
+	metadata = sanitiseChatMetadata(metadata)
+	if err := saveConversationMessage(sessionID, "user", userMessage, nil, metadata); err != nil {
+		return nil, fmt.Errorf("saving user message: %w", err)
+	}
+
+	history, err := GetConversationHistory(sessionID, historyLimit)
+	if err != nil {
+		return nil, fmt.Errorf("loading history: %w", err)
+	}
+
+	systemPrompt := buildSystemPrompt()
+	summary, summarisedCount, err := latestSessionSummary(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("loading session summary: %w", err)
+	}
+	if summary != "" {
+		systemPrompt = "Summary of the conversation so far:\n" + summary + "\n\n" + systemPrompt
+	}
+
+	// If the session just resumed mid-booking, remind the model what it was
+	// doing: inject the last executed function when it ran under a minute
+	// ago.
+	if call, at, err := latestFunctionCallWithTime(sessionID); err != nil {
+		return nil, fmt.Errorf("loading last function call: %w", err)
+	} else if call != nil && time.Since(at) < time.Minute {
+		systemPrompt += fmt.Sprintf(
+			"\n\nThe last function you executed in this session was %s with arguments %v. Continue from there.",
+			call.Name, call.Arguments)
+	}
+
+	messages := make([]models.ChatMessage, 0, len(history)+2)
+	messages = append(messages, models.ChatMessage{Role: "system", Content: systemPrompt})
+	messages = append(messages, history...)
+	if len(metadata) > 0 {
+		messages = append(messages, models.ChatMessage{
+			Role: "system", Content: "User context: " + formatChatMetadata(metadata),
+		})
+	}
+
+	provider := activeProvider()
+	breaker := breakerFor(provider)
+	if !breaker.Allow() {
+		return &models.ChatResponse{
+			SessionID: sessionID,
+			Message:   "The AI service is temporarily unavailable. Please try again in a minute.",
+		}, nil
+	}
+
+	var reply string
+	var functionCall *models.FunctionCall
+	switch provider {
+	case "openai":
+		reply, functionCall, err = callOpenAI(ctx, sessionID, messages)
+	case "anthropic":
+		reply, functionCall, err = callAnthropic(ctx, sessionID, messages)
+	case "ollama":
+		reply, err = callOllama(ctx, messages)
+	default:
+		err = fmt.Errorf("unknown AI provider %q", provider)
+	}
+	if err != nil {
+		breaker.RecordFailure()
+		return nil, err
+	}
+	breaker.RecordSuccess()
+
+	if err := SaveConversationMessage(sessionID, "assistant", reply, functionCall); err != nil {
+		return nil, fmt.Errorf("saving assistant message: %w", err)
+	}
+
+	// Refresh the rolling summary in the background once the session has
+	// grown by a full history window since the last one.
+	go maybeSummariseSession(sessionID, summarisedCount)
+
+	return &models.ChatResponse{
+		SessionID:    sessionID,
+		Message:      reply,
+		FunctionCall: functionCall,
+	}, nil
+}
+
+// sanitiseChatMetadata cleans client-supplied metadata before it reaches the
+// prompt or the database: keys and values are stripped to alphanumerics plus
+// common punctuation, capped at 50 characters, and empty entries dropped.
+func sanitiseChatMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	clean := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		k, v = sanitiseMetadataString(k), sanitiseMetadataString(v)
+		if k != "" && v != "" {
+			clean[k] = v
+		}
+	}
+	if len(clean) == 0 {
+		return nil
+	}
+	return clean
+}
+
+// sanitiseMetadataString keeps letters, digits, spaces and common punctuation
+// and truncates to 50 characters.
+func sanitiseMetadataString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune(" .,:;-_/@+()'", r):
+		default:
+			continue
+		}
+		if b.Len() >= 50 {
+			break
+		}
+		b.WriteRune(r)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// formatChatMetadata renders metadata as "origin=Milano, destination=Roma",
+// with keys sorted for a stable prompt.
+func formatChatMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+metadata[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// buildSystemPrompt returns the system prompt describing the assistant's
+// role, the fleet and the fare rules. The train type section is generated
+// from GetTrainTypes so the prompt stays in sync with /api/train-types.
+func buildSystemPrompt() string {
+	return `You are the booking assistant of an Italian railway. You help users search trains, book tickets, look up and cancel bookings. Always answer in the user's language.
+
+Train types:
+` + trainTypePromptLines() + `
+
+Passenger types and discounts: ` + passengerTypePromptLine() + `.
+
+Users can also ask about stations near a given station (find_nearby_stations).
+
+When a user asks for "direct trains only", pass "filters":{"max_connections":0} in the search so journeys with changes are excluded.
+
+Times are shown in each station's local timezone; on routes crossing timezones the abbreviation is appended (e.g. "08:00 CET").
+
+Use the available functions to search and book. Never invent schedules or prices: always call search_trains first. When the user states an arrival deadline ("I need to be in Rome by 10:00"), use search_trains_by_arrival instead. Confirm the details (route, date, passengers) with the user before booking. Dates are YYYY-MM-DD.`
+}
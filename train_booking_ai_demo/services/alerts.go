@@ -0,0 +1,126 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"trainbooking/models"
+)
+
+// alertSeverities are the accepted advisory levels.
+var alertSeverities = map[string]bool{"info": true, "warning": true, "disruption": true}
+
+// CreateScheduleAlert attaches a new advisory to a schedule.
+func CreateScheduleAlert(alert models.ScheduleAlert) (*models.ScheduleAlert, error) {
+	if !alertSeverities[alert.Severity] {
+		return nil, fmt.Errorf("invalid severity %q: must be info, warning or disruption", alert.Severity)
+	}
+	if alert.Message == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if !alert.ValidTo.After(alert.ValidFrom) {
+		return nil, fmt.Errorf("valid_to must be after valid_from")
+	}
+
+	err := db.QueryRow(`
+		INSERT INTO schedule_alerts
+			(schedule_id, severity, message, valid_from, valid_to, created_by_user_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		alert.ScheduleID, alert.Severity, alert.Message,
+		alert.ValidFrom, alert.ValidTo, alert.CreatedByUserID,
+	).Scan(&alert.ID)
+	if err != nil {
+		return nil, fmt.Errorf("creating alert: %w", err)
+	}
+	return &alert, nil
+}
+
+// UpdateScheduleAlert replaces the mutable fields of an advisory.
+func UpdateScheduleAlert(id int, alert models.ScheduleAlert) error {
+	if !alertSeverities[alert.Severity] {
+		return fmt.Errorf("invalid severity %q: must be info, warning or disruption", alert.Severity)
+	}
+	result, err := db.Exec(`
+		UPDATE schedule_alerts
+		SET severity = $1, message = $2, valid_from = $3, valid_to = $4
+		WHERE id = $5`,
+		alert.Severity, alert.Message, alert.ValidFrom, alert.ValidTo, id)
+	if err != nil {
+		return fmt.Errorf("updating alert %d: %w", id, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("alert %d not found", id)
+	}
+	return nil
+}
+
+// DeleteScheduleAlert removes an advisory.
+func DeleteScheduleAlert(id int) error {
+	result, err := db.Exec(`DELETE FROM schedule_alerts WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting alert %d: %w", id, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("alert %d not found", id)
+	}
+	return nil
+}
+
+// GetScheduleAlerts returns every advisory of one schedule, newest first.
+func GetScheduleAlerts(scheduleID int) ([]models.ScheduleAlert, error) {
+	rows, err := db.Query(`
+		SELECT id, schedule_id, severity, message, valid_from, valid_to,
+		       COALESCE(created_by_user_id, 0)
+		FROM schedule_alerts
+		WHERE schedule_id = $1
+		ORDER BY valid_from DESC`, scheduleID)
+	if err != nil {
+		return nil, fmt.Errorf("loading alerts for schedule %d: %w", scheduleID, err)
+	}
+	return scanAlerts(rows)
+}
+
+// activeAlertsForSchedules fetches the advisories valid on one date for a
+// set of schedules, keyed by schedule id. Used to decorate search results.
+func activeAlertsForSchedules(scheduleIDs []int, date time.Time) (map[int][]models.ScheduleAlert, error) {
+	if len(scheduleIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := db.Query(`
+		SELECT id, schedule_id, severity, message, valid_from, valid_to,
+		       COALESCE(created_by_user_id, 0)
+		FROM schedule_alerts
+		WHERE schedule_id = ANY($1) AND valid_from <= $2 AND valid_to >= $2
+		ORDER BY valid_from`, pq.Array(scheduleIDs), date)
+	if err != nil {
+		return nil, fmt.Errorf("loading active alerts: %w", err)
+	}
+	alerts, err := scanAlerts(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	bySchedule := make(map[int][]models.ScheduleAlert)
+	for _, a := range alerts {
+		bySchedule[a.ScheduleID] = append(bySchedule[a.ScheduleID], a)
+	}
+	return bySchedule, nil
+}
+
+func scanAlerts(rows *sql.Rows) ([]models.ScheduleAlert, error) {
+	defer rows.Close()
+	var alerts []models.ScheduleAlert
+	for rows.Next() {
+		var a models.ScheduleAlert
+		if err := rows.Scan(&a.ID, &a.ScheduleID, &a.Severity, &a.Message,
+			&a.ValidFrom, &a.ValidTo, &a.CreatedByUserID); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"fmt"
+	"time"
+)
+
+// FrequentTraveller is one row of the marketing report on active passengers.
+type FrequentTraveller struct {
+	Name            string    `json:"name"`
+	BookingCount    int       `json:"booking_count"`
+	TotalSpend      float64   `json:"total_spend"`
+	FavouriteRoute  string    `json:"favourite_route"`
+	LastBookingDate time.Time `json:"last_booking_date"`
+}
+
+// DayCount is one point of the booking-count time series.
+type DayCount struct {
+	Date      string  `json:"date"`
+	Confirmed int     `json:"confirmed"`
+	Cancelled int     `json:"cancelled"`
+	Revenue   float64 `json:"revenue"`
+}
+
+// maxAnalyticsRangeDays caps time-series queries to one year.
+const maxAnalyticsRangeDays = 365
+
+// GetBookingCountByDay returns the daily booking counts and confirmed
+// revenue between two dates (by created_at). The range is capped at a year.
+func GetBookingCountByDay(from, to time.Time) ([]DayCount, error) {
+	return bookingCountSeries(from, to, "day")
+}
+
+// GetBookingCountByWeek is the weekly rollup of GetBookingCountByDay; dates
+// are the Mondays starting each week.
+func GetBookingCountByWeek(from, to time.Time) ([]DayCount, error) {
+	return bookingCountSeries(from, to, "week")
+}
+
+func bookingCountSeries(from, to time.Time, truncUnit string) ([]DayCount, error) {
+	if to.Sub(from) > maxAnalyticsRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("date range too large: at most %d days", maxAnalyticsRangeDays)
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT to_char(DATE_TRUNC('%s', created_at), 'YYYY-MM-DD'),
+		       COUNT(*) FILTER (WHERE status = 'confirmed'),
+		       COUNT(*) FILTER (WHERE status = 'cancelled'),
+		       COALESCE(SUM(total_price) FILTER (WHERE status = 'confirmed'), 0)
+		FROM bookings
+		WHERE created_at BETWEEN $1 AND $2
+		GROUP BY 1
+		ORDER BY 1`, truncUnit), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("counting bookings by %s: %w", truncUnit, err)
+	}
+	defer rows.Close()
+
+	var series []DayCount
+	for rows.Next() {
+		var d DayCount
+		if err := rows.Scan(&d.Date, &d.Confirmed, &d.Cancelled, &d.Revenue); err != nil {
+			return nil, err
+		}
+		series = append(series, d)
+	}
+	return series, rows.Err()
+}
+
+// RevenueReport is the per-schedule breakdown used by finance.
+type RevenueReport struct {
+	ScheduleID             int                `json:"schedule_id"`
+	TrainNumber            string             `json:"train_number"`
+	Route                  string             `json:"route"`
+	Period                 string             `json:"period"`
+	TotalRevenue           float64            `json:"total_revenue"`
+	BookingCount           int                `json:"booking_count"`
+	PassengerCount         int                `json:"passenger_count"`
+	AvgRevenuePerPassenger float64            `json:"avg_revenue_per_passenger"`
+	RevenueByPassengerType map[string]float64 `json:"revenue_by_passenger_type"`
+	RefundedRevenue        float64            `json:"refunded_revenue"`
+}
+
+// GetScheduleRevenue sums the confirmed revenue of one schedule over a
+// travel-date range, broken down by passenger type, plus what was paid back
+// on cancellations.
+func GetScheduleRevenue(scheduleID int, from, to time.Time) (RevenueReport, error) {
+	report := RevenueReport{
+		ScheduleID:             scheduleID,
+		Period:                 fmt.Sprintf("%s - %s", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		RevenueByPassengerType: make(map[string]float64),
+	}
+
+	trainNumber, route, err := GetScheduleSummary(scheduleID)
+	if err != nil {
+		return report, err
+	}
+	report.TrainNumber = trainNumber
+	report.Route = route
+
+	err = db.QueryRow(`
+		SELECT COALESCE(SUM(b.total_price) FILTER (WHERE b.status = 'confirmed'), 0),
+		       COUNT(*) FILTER (WHERE b.status = 'confirmed'),
+		       COALESCE(SUM(b.passenger_count) FILTER (WHERE b.status = 'confirmed'), 0),
+		       COALESCE(SUM(b.refund_amount), 0)
+		FROM bookings b
+		WHERE b.schedule_id = $1 AND b.booking_date BETWEEN $2 AND $3`,
+		scheduleID, from, to,
+	).Scan(&report.TotalRevenue, &report.BookingCount, &report.PassengerCount, &report.RefundedRevenue)
+	if err != nil {
+		return report, fmt.Errorf("summing revenue for schedule %d: %w", scheduleID, err)
+	}
+	if report.PassengerCount > 0 {
+		report.AvgRevenuePerPassenger = round2(report.TotalRevenue / float64(report.PassengerCount))
+	}
+
+	rows, err := db.Query(`
+		SELECT p.passenger_type, COALESCE(SUM(p.price), 0)
+		FROM passengers p
+		JOIN bookings b ON b.id = p.booking_id
+		WHERE b.schedule_id = $1 AND b.status = 'confirmed'
+		  AND b.booking_date BETWEEN $2 AND $3
+		GROUP BY p.passenger_type`,
+		scheduleID, from, to)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var passengerType string
+		var revenue float64
+		if err := rows.Scan(&passengerType, &revenue); err != nil {
+			return report, err
+		}
+		report.RevenueByPassengerType[passengerType] = revenue
+	}
+	return report, rows.Err()
+}
+
+// FunctionCallStats summarises how one AI function was used in a period.
+type FunctionCallStats struct {
+	CallCount int `json:"call_count"`
+	// SuccessCount counts calls after which the assistant produced a
+	// non-error reply; execution outcomes are not stored separately.
+	SuccessCount          int     `json:"success_count"`
+	AvgArgCount           float64 `json:"avg_arg_count"`
+	MostCommonOrigin      string  `json:"most_common_origin,omitempty"`
+	MostCommonDestination string  `json:"most_common_destination,omitempty"`
+}
+
+// GetAIFunctionCallStats aggregates conversation_history.function_call per
+// function name, parsing the JSON column in SQL.
+func GetAIFunctionCallStats(from, to time.Time) (map[string]FunctionCallStats, error) {
+	rows, err := db.Query(`
+		SELECT jsonb_extract_path_text(function_call, 'name'),
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE message <> '' AND message NOT LIKE 'Error:%'),
+		       AVG((SELECT COUNT(*) FROM jsonb_object_keys(
+		            COALESCE(function_call -> 'arguments', '{}'::jsonb)))),
+		       COALESCE(mode() WITHIN GROUP (
+		            ORDER BY jsonb_extract_path_text(function_call, 'arguments', 'origin')), ''),
+		       COALESCE(mode() WITHIN GROUP (
+		            ORDER BY jsonb_extract_path_text(function_call, 'arguments', 'destination')), '')
+		FROM conversation_history
+		WHERE function_call IS NOT NULL
+		  AND timestamp BETWEEN $1 AND $2
+		GROUP BY jsonb_extract_path_text(function_call, 'name')`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("aggregating function calls: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string]FunctionCallStats)
+	for rows.Next() {
+		var name string
+		var s FunctionCallStats
+		if err := rows.Scan(
+			&name, &s.CallCount, &s.SuccessCount, &s.AvgArgCount,
+			&s.MostCommonOrigin, &s.MostCommonDestination,
+		); err != nil {
+			return nil, err
+		}
+		stats[name] = s
+	}
+	return stats, rows.Err()
+}
+
+// FlowNode is one edge of the conversation-flow Sankey diagram: how often
+// one AI function was followed by another within the same session.
+type FlowNode struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+// GetConversationFlowStats aggregates consecutive function calls per session
+// into transition counts, e.g. search_trains -> create_booking. Top 20
+// transitions, busiest first.
+func GetConversationFlowStats() ([]FlowNode, error) {
+	rows, err := db.Query(`
+		SELECT prev, name, COUNT(*)
+		FROM (
+		    SELECT LAG(function_call ->> 'name') OVER (
+		               PARTITION BY session_id ORDER BY timestamp) AS prev,
+		           function_call ->> 'name' AS name
+		    FROM conversation_history
+		    WHERE function_call IS NOT NULL
+		) transitions
+		WHERE prev IS NOT NULL
+		GROUP BY prev, name
+		ORDER BY COUNT(*) DESC
+		LIMIT 20`)
+	if err != nil {
+		return nil, fmt.Errorf("aggregating conversation flow: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []FlowNode
+	for rows.Next() {
+		var n FlowNode
+		if err := rows.Scan(&n.From, &n.To, &n.Count); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, rows.Err()
+}
+
+// GetFrequentTravellers aggregates passengers by name (case-insensitive)
+// across all bookings. minBookings filters out occasional travellers; pass 0
+// to include everyone.
+func GetFrequentTravellers(limit, minBookings int) ([]FrequentTraveller, error) {
+	if limit < 1 {
+		limit = 20
+	}
+	rows, err := db.Query(`
+		SELECT initcap(lower(p.name)),
+		       COUNT(DISTINCT b.id),
+		       SUM(p.price),
+		       mode() WITHIN GROUP (ORDER BY o.name || ' -> ' || d.name),
+		       MAX(b.created_at)
+		FROM passengers p
+		JOIN bookings b ON b.id = p.booking_id
+		JOIN schedules s ON s.id = b.schedule_id
+		JOIN stations o ON o.id = s.origin_id
+		JOIN stations d ON d.id = s.destination_id
+		GROUP BY lower(p.name)
+		HAVING COUNT(DISTINCT b.id) >= $1
+		ORDER BY COUNT(DISTINCT b.id) DESC, SUM(p.price) DESC
+		LIMIT $2`, minBookings, limit)
+	if err != nil {
+		return nil, fmt.Errorf("aggregating frequent travellers: %w", err)
+	}
+	defer rows.Close()
+
+	var travellers []FrequentTraveller
+	for rows.Next() {
+		var t FrequentTraveller
+		if err := rows.Scan(
+			&t.Name, &t.BookingCount, &t.TotalSpend, &t.FavouriteRoute, &t.LastBookingDate,
+		); err != nil {
+			return nil, err
+		}
+		travellers = append(travellers, t)
+	}
+	return travellers, rows.Err()
+}
+
+// TrainTypeStats is one train type's row in the operations overview.
+type TrainTypeStats struct {
+	Type            string  `json:"type"`
+	TrainCount      int     `json:"train_count"`
+	ScheduleCount   int     `json:"schedule_count"`
+	TotalBookings   int     `json:"total_bookings"`
+	AvgOccupancyPct float64 `json:"avg_occupancy_pct"`
+	TotalRevenue    float64 `json:"total_revenue"`
+}
+
+// GetTrainTypeStats aggregates fleet usage per train type (FR, IC, RG):
+// fleet and schedule sizes, plus confirmed bookings and revenue with travel
+// dates in the given range. Sorted by revenue, most profitable type first.
+func GetTrainTypeStats(from, to time.Time) ([]TrainTypeStats, error) {
+	rows, err := db.Query(`
+		SELECT t.type,
+		       COUNT(DISTINCT t.id),
+		       COUNT(DISTINCT s.id),
+		       COUNT(b.id) FILTER (WHERE b.status = 'confirmed'),
+		       COALESCE(AVG((s.total_seats - s.available_seats)::float8 / NULLIF(s.total_seats, 0) * 100), 0),
+		       COALESCE(SUM(b.total_price) FILTER (WHERE b.status = 'confirmed'), 0)
+		FROM trains t
+		LEFT JOIN schedules s ON s.train_id = t.id AND s.is_active
+		LEFT JOIN bookings b ON b.schedule_id = s.id AND b.booking_date BETWEEN $1 AND $2
+		GROUP BY t.type
+		ORDER BY 6 DESC`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("aggregating train types: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []TrainTypeStats
+	for rows.Next() {
+		var s TrainTypeStats
+		if err := rows.Scan(
+			&s.Type, &s.TrainCount, &s.ScheduleCount, &s.TotalBookings,
+			&s.AvgOccupancyPct, &s.TotalRevenue,
+		); err != nil {
+			return nil, err
+		}
+		s.AvgOccupancyPct = round2(s.AvgOccupancyPct)
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// HourlyStats is one departure hour's popularity on a route.
+type HourlyStats struct {
+	Hour            int     `json:"hour"`
+	BookingCount    int     `json:"booking_count"`
+	AvgOccupancyPct float64 `json:"avg_occupancy_pct"`
+}
+
+// GetSchedulePeakHours aggregates confirmed bookings on a route by departure
+// hour. All 24 hours are returned, with zeros where nothing departs, so the
+// frontend can render a full histogram.
+func GetSchedulePeakHours(originCode, destinationCode string) ([]HourlyStats, error) {
+	origin, err := FindStationByNameOrCode(originCode)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := FindStationByNameOrCode(destinationCode)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT EXTRACT(HOUR FROM s.departure_time)::int,
+		       COUNT(b.id),
+		       COALESCE(AVG((s.total_seats - s.available_seats)::float8 / NULLIF(s.total_seats, 0) * 100), 0)
+		FROM schedules s
+		LEFT JOIN bookings b ON b.schedule_id = s.id AND b.status = 'confirmed'
+		WHERE s.origin_id = $1 AND s.destination_id = $2 AND s.is_active
+		GROUP BY EXTRACT(HOUR FROM s.departure_time)`,
+		origin.ID, destination.ID)
+	if err != nil {
+		return nil, fmt.Errorf("aggregating peak hours: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]HourlyStats, 24)
+	for i := range stats {
+		stats[i].Hour = i
+	}
+	for rows.Next() {
+		var hour, count int
+		var occupancy float64
+		if err := rows.Scan(&hour, &count, &occupancy); err != nil {
+			return nil, err
+		}
+		if hour < 0 || hour > 23 {
+			continue
+		}
+		stats[hour].BookingCount = count
+		stats[hour].AvgOccupancyPct = round2(occupancy)
+	}
+	return stats, rows.Err()
+}
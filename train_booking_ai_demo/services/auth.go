@@ -0,0 +1,41 @@
+package services
+
+import (
+	"fmt"
+	"time"
+)
+
+// BlockToken adds a token id to the blocklist until the token would have
+// expired anyway, after which PruneExpiredBlocklistEntries drops it.
+func BlockToken(jti string, expiresAt time.Time) error {
+	_, err := db.Exec(
+		`INSERT INTO jwt_blocklist (jti, expires_at) VALUES ($1, $2)
+		 ON CONFLICT (jti) DO NOTHING`,
+		jti, expiresAt)
+	if err != nil {
+		return fmt.Errorf("blocking token: %w", err)
+	}
+	return nil
+}
+
+// IsTokenBlocked reports whether a token id has been revoked.
+func IsTokenBlocked(jti string) (bool, error) {
+	var blocked bool
+	err := db.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM jwt_blocklist WHERE jti = $1)`, jti,
+	).Scan(&blocked)
+	if err != nil {
+		return false, fmt.Errorf("checking token blocklist: %w", err)
+	}
+	return blocked, nil
+}
+
+// PruneExpiredBlocklistEntries removes blocklist rows whose tokens have
+// expired on their own; run periodically.
+func PruneExpiredBlocklistEntries() (int64, error) {
+	result, err := db.Exec(`DELETE FROM jwt_blocklist WHERE expires_at < now()`)
+	if err != nil {
+		return 0, fmt.Errorf("pruning token blocklist: %w", err)
+	}
+	return result.RowsAffected()
+}
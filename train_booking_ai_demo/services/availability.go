@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"trainbooking/models"
+)
+
+// AvailabilitySummary answers "is there any train at all?" without the full
+// schedule list.
+type AvailabilitySummary struct {
+	Available         bool    `json:"available"`
+	TrainCount        int     `json:"train_count"`
+	EarliestDeparture string  `json:"earliest_departure,omitempty"`
+	LatestDeparture   string  `json:"latest_departure,omitempty"`
+	LowestPrice       float64 `json:"lowest_price,omitempty"`
+}
+
+// availabilityCacheTTL bounds DB load from availability polling.
+const availabilityCacheTTL = 60 * time.Second
+
+type availabilityCacheEntry struct {
+	summary AvailabilitySummary
+	expires time.Time
+}
+
+var availabilityCache sync.Map
+
+// CheckAvailability summarises the search results for a route and date.
+// Results are cached for 60 seconds, so a summary can lag bookings briefly.
+func CheckAvailability(origin, destination, date string) (*AvailabilitySummary, error) {
+	key := fmt.Sprintf("%s|%s|%s", origin, destination, date)
+	if cached, ok := availabilityCache.Load(key); ok {
+		entry := cached.(availabilityCacheEntry)
+		if time.Now().Before(entry.expires) {
+			summary := entry.summary
+			return &summary, nil
+		}
+	}
+
+	results, err := SearchTrains(models.SearchRequest{
+		Origin: origin, Destination: destination, Date: date,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := AvailabilitySummary{TrainCount: len(results)}
+	for _, r := range results {
+		if !r.IsAvailable {
+			continue
+		}
+		summary.Available = true
+		if summary.EarliestDeparture == "" || r.DepartureTime < summary.EarliestDeparture {
+			summary.EarliestDeparture = r.DepartureTime
+		}
+		if r.DepartureTime > summary.LatestDeparture {
+			summary.LatestDeparture = r.DepartureTime
+		}
+		if summary.LowestPrice == 0 || r.PricePerAdult < summary.LowestPrice {
+			summary.LowestPrice = r.PricePerAdult
+		}
+	}
+
+	availabilityCache.Store(key, availabilityCacheEntry{
+		summary: summary,
+		expires: time.Now().Add(availabilityCacheTTL),
+	})
+	return &summary, nil
+}
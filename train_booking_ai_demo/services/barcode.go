@@ -0,0 +1,135 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// code128Widths holds the bar/space module widths for Code128 symbol values
+// 0-105 (ISO/IEC 15417). Each symbol is six alternating bar and space widths
+// summing to 11 modules, starting with a bar.
+var code128Widths = []string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213",
+	"122312", "132212", "221213", "221312", "231212", "112232", "122132",
+	"122231", "113222", "123122", "123221", "223211", "221132", "221231",
+	"213212", "223112", "312131", "311222", "321122", "321221", "312212",
+	"322112", "322211", "212123", "212321", "232121", "111323", "131123",
+	"131321", "112313", "132113", "132311", "211313", "231113", "231311",
+	"112133", "112331", "132131", "113123", "113321", "133121", "313121",
+	"211331", "231131", "213113", "213311", "213131", "311123", "311321",
+	"331121", "312113", "312311", "332111", "314111", "221411", "431111",
+	"111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114",
+	"413111", "241112", "134111", "111242", "121142", "121241", "114212",
+	"124112", "124211", "411212", "421112", "421211", "212141", "214121",
+	"412121", "111143", "111341", "131141", "114113", "114311", "411113",
+	"411311", "113141", "114131", "311141", "411131", "211412", "211214",
+	"211232",
+}
+
+const (
+	code128StartB = 104
+	// code128StopWidths is the stop pattern: 13 modules, seven elements.
+	code128StopWidths = "2331112"
+	// code128QuietZone is the minimum quiet zone either side, in modules.
+	code128QuietZone = 10
+)
+
+// encodeCode128 encodes text with Code Set B (printable ASCII) and returns
+// the barcode as modules, true for a bar. The quiet zones are not included.
+func encodeCode128(text string) ([]bool, error) {
+	if text == "" {
+		return nil, fmt.Errorf("cannot encode empty text")
+	}
+	symbols := []int{code128StartB}
+	for _, r := range text {
+		if r < 32 || r > 126 {
+			return nil, fmt.Errorf("character %q cannot be encoded with Code128 set B", r)
+		}
+		symbols = append(symbols, int(r)-32)
+	}
+
+	checksum := symbols[0]
+	for i, v := range symbols[1:] {
+		checksum += (i + 1) * v
+	}
+	symbols = append(symbols, checksum%103)
+
+	var modules []bool
+	for _, v := range symbols {
+		modules = appendWidths(modules, code128Widths[v])
+	}
+	return appendWidths(modules, code128StopWidths), nil
+}
+
+// appendWidths expands a widths string ("212222") into modules, alternating
+// bar and space starting with a bar.
+func appendWidths(modules []bool, widths string) []bool {
+	for i, w := range widths {
+		for n := 0; n < int(w-'0'); n++ {
+			modules = append(modules, i%2 == 0)
+		}
+	}
+	return modules
+}
+
+// renderBarcodePNG draws the modules as a width×height black-on-white PNG.
+// Bars are scaled to the largest integer module width that fits inside the
+// quiet zones and centred horizontally.
+func renderBarcodePNG(modules []bool, width, height int) ([]byte, error) {
+	scale := width / (len(modules) + 2*code128QuietZone)
+	if scale < 1 {
+		return nil, fmt.Errorf("barcode with %d modules does not fit in %d pixels", len(modules), width)
+	}
+	left := (width - len(modules)*scale) / 2
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	for i, bar := range modules {
+		if !bar {
+			continue
+		}
+		for x := left + i*scale; x < left+(i+1)*scale; x++ {
+			for y := 0; y < height; y++ {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateBookingBarcode renders a booking reference as a 400×100 Code128
+// PNG for conductors to scan. The barcode content is the raw reference
+// string. Implemented in-process (stdlib image only) to keep the demo
+// dependency-free.
+func GenerateBookingBarcode(bookingRef string) ([]byte, error) {
+	var exists bool
+	err := db.QueryRow(
+		`SELECT true FROM bookings WHERE booking_ref = $1`, bookingRef,
+	).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("booking %s not found", bookingRef)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	modules, err := encodeCode128(bookingRef)
+	if err != nil {
+		return nil, err
+	}
+	return renderBarcodePNG(modules, 400, 100)
+}
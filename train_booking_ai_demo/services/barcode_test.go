@@ -0,0 +1,134 @@
+package services
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+// decodeCode128PNG reads back a barcode rendered by renderBarcodePNG: it
+// scans the middle row for bar/space runs, normalises them to module widths,
+// strips the stop pattern and maps each 6-element symbol back to its value.
+func decodeCode128PNG(t *testing.T, data []byte) string {
+	t.Helper()
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decoding PNG: %v", err)
+	}
+	bounds := img.Bounds()
+	y := bounds.Min.Y + bounds.Dy()/2
+
+	first, last := -1, -1
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		r, _, _, _ := img.At(x, y).RGBA()
+		if r < 0x8000 {
+			if first == -1 {
+				first = x
+			}
+			last = x
+		}
+	}
+	if first == -1 {
+		t.Fatal("no bars found in barcode image")
+	}
+
+	var runs []int
+	runLength, dark := 0, true
+	for x := first; x <= last; x++ {
+		r, _, _, _ := img.At(x, y).RGBA()
+		if (r < 0x8000) == dark {
+			runLength++
+			continue
+		}
+		runs = append(runs, runLength)
+		runLength, dark = 1, !dark
+	}
+	runs = append(runs, runLength)
+
+	unit := runs[0]
+	for _, r := range runs {
+		if r < unit {
+			unit = r
+		}
+	}
+	widths := make([]int, len(runs))
+	for i, r := range runs {
+		if r%unit != 0 {
+			t.Fatalf("run %d of %dpx is not a whole number of %dpx modules", i, r, unit)
+		}
+		widths[i] = r / unit
+	}
+
+	if len(widths) < 13 || (len(widths)-7)%6 != 0 {
+		t.Fatalf("unexpected element count %d", len(widths))
+	}
+	stop := widths[len(widths)-7:]
+	for i, w := range code128StopWidths {
+		if stop[i] != int(w-'0') {
+			t.Fatalf("stop pattern mismatch: got %v", stop)
+		}
+	}
+	widths = widths[:len(widths)-7]
+
+	valueFor := map[string]int{}
+	for v, w := range code128Widths {
+		valueFor[w] = v
+	}
+
+	var symbols []int
+	for i := 0; i < len(widths); i += 6 {
+		key := ""
+		for _, w := range widths[i : i+6] {
+			key += string(rune('0' + w))
+		}
+		v, ok := valueFor[key]
+		if !ok {
+			t.Fatalf("unknown symbol pattern %s", key)
+		}
+		symbols = append(symbols, v)
+	}
+
+	if symbols[0] != code128StartB {
+		t.Fatalf("expected start B (%d), got %d", code128StartB, symbols[0])
+	}
+	checksum := symbols[0]
+	for i, v := range symbols[1 : len(symbols)-1] {
+		checksum += (i + 1) * v
+	}
+	if got := symbols[len(symbols)-1]; got != checksum%103 {
+		t.Fatalf("checksum mismatch: got %d, want %d", got, checksum%103)
+	}
+
+	decoded := ""
+	for _, v := range symbols[1 : len(symbols)-1] {
+		decoded += string(rune(v + 32))
+	}
+	return decoded
+}
+
+func TestGenerateBookingBarcodeRoundTrip(t *testing.T) {
+	const ref = "TRN-2025-00001"
+
+	modules, err := encodeCode128(ref)
+	if err != nil {
+		t.Fatalf("encodeCode128: %v", err)
+	}
+	data, err := renderBarcodePNG(modules, 400, 100)
+	if err != nil {
+		t.Fatalf("renderBarcodePNG: %v", err)
+	}
+
+	if got := decodeCode128PNG(t, data); got != ref {
+		t.Fatalf("decoded %q, want %q", got, ref)
+	}
+}
+
+func TestEncodeCode128RejectsNonASCII(t *testing.T) {
+	if _, err := encodeCode128("TRN-2025-à"); err == nil {
+		t.Fatal("expected error for non-ASCII input")
+	}
+	if _, err := encodeCode128(""); err == nil {
+		t.Fatal("expected error for empty input")
+	}
+}
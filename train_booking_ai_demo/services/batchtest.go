@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+//go:embed test_cases.json
+var aiTestCasesJSON []byte
+
+// batchTestConcurrency caps how many test prompts run against the provider
+// at once.
+const batchTestConcurrency = 5
+
+// TestCase is one prompt of the AI regression suite with the function call
+// it is expected to produce.
+type TestCase struct {
+	Input            string            `json:"input"`
+	ExpectedFunction string            `json:"expected_function"`
+	ExpectedArgs     map[string]string `json:"expected_args"`
+}
+
+// TestCaseResult describes one failed test case.
+type TestCaseResult struct {
+	Input    string `json:"input"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// BatchTestReport summarises one run of the AI regression suite.
+type BatchTestReport struct {
+	Total    int              `json:"total"`
+	Passed   int              `json:"passed"`
+	Failed   int              `json:"failed"`
+	Failures []TestCaseResult `json:"failures"`
+}
+
+// RunAIBatchTest replays the embedded test prompts against the active
+// provider, up to batchTestConcurrency at a time, and scores whether each
+// produced the expected function call. Every case runs in its own throwaway
+// "__test__" session, which is purged afterwards so real conversations are
+// not polluted.
+func RunAIBatchTest(ctx context.Context) (*BatchTestReport, error) {
+	var cases []TestCase
+	if err := json.Unmarshal(aiTestCasesJSON, &cases); err != nil {
+		return nil, fmt.Errorf("parsing embedded test cases: %w", err)
+	}
+
+	report := &BatchTestReport{Total: len(cases), Failures: []TestCaseResult{}}
+	var mu sync.Mutex
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(batchTestConcurrency)
+	for i, tc := range cases {
+		i, tc := i, tc
+		g.Go(func() error {
+			sessionID := fmt.Sprintf("__test__%d_%d", time.Now().UnixNano(), i)
+			defer func() {
+				if _, err := DeleteSession(sessionID); err != nil {
+					log.Printf("purging test session %s: %v", sessionID, err)
+				}
+			}()
+
+			actual := ""
+			resp, err := ProcessMessage(ctx, sessionID, tc.Input, nil)
+			switch {
+			case err != nil:
+				actual = fmt.Sprintf("error: %v", err)
+			case resp.FunctionCall == nil:
+				actual = "no function call"
+			default:
+				actual = resp.FunctionCall.Name
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if actual == tc.ExpectedFunction {
+				report.Passed++
+				return nil
+			}
+			report.Failed++
+			report.Failures = append(report.Failures, TestCaseResult{
+				Input: tc.Input, Expected: tc.ExpectedFunction, Actual: actual,
+			})
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,784 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"regexp"
+	"time"
+
+	"trainbooking/models"
+)
+
+// ErrModificationLimit is returned when a booking has used up its allowed
+// modifications (MAX_BOOKING_MODIFICATIONS).
+var ErrModificationLimit = errors.New("booking has already been modified the maximum number of times")
+
+// emailRegex is a pragmatic format check; real validation happens when the
+// confirmation email bounces.
+var emailRegex = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// promoCodes maps active promo codes to their discount fraction.
+var promoCodes = map[string]float64{
+	"BENVENUTO10": 0.10,
+	"ESTATE25":    0.25,
+}
+
+// CalculatePassengerPrice applies the per-type discount to a base price,
+// per the passengerTypes definitions. Unknown types pay the adult fare.
+func CalculatePassengerPrice(basePrice float64, passengerType string) float64 {
+	def, ok := passengerTypeByCode(passengerType)
+	if !ok {
+		return basePrice // adult
+	}
+	return basePrice * (1 - def.DiscountPercent/100)
+}
+
+// PassengerTypePricing describes one pricing tier in a form the AI and the
+// frontend can present directly.
+type PassengerTypePricing struct {
+	Type         string  `json:"type"`
+	Description  string  `json:"description"`
+	DiscountPct  float64 `json:"discount_pct"`
+	ExamplePrice float64 `json:"example_price"`
+}
+
+// examplePassengerBasePrice is the representative adult fare used to
+// illustrate the discount tiers.
+const examplePassengerBasePrice = 50.00
+
+// GetPassengerTypePricing lists the pricing tiers with their discounts,
+// computed from CalculatePassengerPrice so the figures cannot drift from
+// what bookings actually charge.
+func GetPassengerTypePricing() []PassengerTypePricing {
+	tiers := GetAllPassengerTypes()
+	pricing := make([]PassengerTypePricing, 0, len(tiers))
+	for _, t := range tiers {
+		price := CalculatePassengerPrice(examplePassengerBasePrice, t.Code)
+		pricing = append(pricing, PassengerTypePricing{
+			Type:         t.Code,
+			Description:  t.Description,
+			DiscountPct:  round2((1 - price/examplePassengerBasePrice) * 100),
+			ExamplePrice: price,
+		})
+	}
+	return pricing
+}
+
+// generateBookingReference produces a new reference in the format selected
+// by BOOKING_REF_FORMAT. Both formats stay parseable side by side, so the
+// format can be switched without migrating existing bookings.
+func generateBookingReference() (string, error) {
+	if cfg.BookingRefFormat == "v2" {
+		return generateBookingReferenceV2()
+	}
+	return generateBookingReferenceV1()
+}
+
+// generateBookingReferenceV1 produces TRN-YYYY-NNNNN, where NNNNN is a
+// zero-padded per-year counter. It wraps (and may collide) after 99999
+// bookings in one year; v2 exists for that reason.
+func generateBookingReferenceV1() (string, error) {
+	year := time.Now().Year()
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM bookings WHERE booking_ref LIKE $1`,
+		fmt.Sprintf("TRN-%d-%%", year),
+	).Scan(&count)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("TRN-%d-%05d", year, count+1), nil
+}
+
+// bookingRefV2Alphabet is base36, matching strconv's lowercase digits.
+const bookingRefV2Alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// generateBookingReferenceV2 produces TRN-YYYYMMDD-XXXXXXXX with an 8-char
+// random base36 suffix, collision-free for any realistic volume.
+func generateBookingReferenceV2() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = bookingRefV2Alphabet[int(b)%len(bookingRefV2Alphabet)]
+	}
+	return fmt.Sprintf("TRN-%s-%s", time.Now().Format("20060102"), buf), nil
+}
+
+// bookingRefV2Pattern matches TRN-YYYYMMDD-XXXXXXXX references.
+var bookingRefV2Pattern = regexp.MustCompile(`^TRN-\d{8}-[0-9a-z]{8}$`)
+
+// GetBookingRefVersion detects which reference format a string uses: "v2",
+// "v1", or "" for strings that are not booking references at all.
+func GetBookingRefVersion(ref string) string {
+	if bookingRefV2Pattern.MatchString(ref) {
+		return "v2"
+	}
+	if bookingRefV1Pattern.MatchString(ref) {
+		return "v1"
+	}
+	return ""
+}
+
+// bookingRefV1Pattern matches TRN-YYYY-NNNNN references.
+var bookingRefV1Pattern = regexp.MustCompile(`^TRN-\d{4}-\d{5}$`)
+
+// generateConfirmationCode produces the 4-digit PIN printed on the
+// confirmation, from crypto/rand so codes are not guessable in sequence.
+func generateConfirmationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(10000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%04d", n.Int64()), nil
+}
+
+// CreateBooking reserves seats on a schedule for a travel date. The whole
+// operation runs in one transaction with the schedule row locked, so
+// concurrent bookings cannot oversell the train.
+func CreateBooking(req models.BookingRequest) (*models.Booking, error) {
+	travelDate, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: use YYYY-MM-DD", req.Date)
+	}
+
+	normalisePassengerNames(req.Passengers)
+	if err := validatePassengerNames(req.Passengers); err != nil {
+		return nil, err
+	}
+	if err := validateLoyaltyCards(req.Passengers); err != nil {
+		return nil, err
+	}
+
+	if req.ContactEmail != "" && !emailRegex.MatchString(req.ContactEmail) {
+		return nil, fmt.Errorf("invalid contact email %q", req.ContactEmail)
+	}
+	if req.ContactEmail == "" {
+		log.Printf("warning: booking without contact email (schedule %d, %s)", req.ScheduleID, req.Date)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var priceBase float64
+	var availableSeats, dayOfWeek, totalSeats int
+	err = tx.QueryRow(
+		`SELECT price_base, available_seats, day_of_week, total_seats
+		 FROM schedules WHERE id = $1 AND is_active FOR UPDATE`,
+		req.ScheduleID,
+	).Scan(&priceBase, &availableSeats, &dayOfWeek, &totalSeats)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("schedule %d not found", req.ScheduleID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if int(travelDate.Weekday()) != dayOfWeek {
+		return nil, fmt.Errorf("schedule %d does not operate on %s", req.ScheduleID, req.Date)
+	}
+
+	// Infants travel on a lap and do not occupy a seat.
+	seatsNeeded := 0
+	for _, p := range req.Passengers {
+		if p.PassengerType != "infant" {
+			seatsNeeded++
+		}
+	}
+	if seatsNeeded > availableSeats {
+		return nil, fmt.Errorf("only %d seats available, %d requested", availableSeats, seatsNeeded)
+	}
+
+	bookingRef, err := generateBookingReference()
+	if err != nil {
+		return nil, err
+	}
+	confirmationCode, err := generateConfirmationCode()
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0.0
+	prices := make([]float64, len(req.Passengers))
+	for i, p := range req.Passengers {
+		prices[i] = CalculatePassengerPrice(priceBase, p.PassengerType)
+		total += prices[i]
+	}
+	if len(req.Passengers) >= cfg.GroupDiscountThreshold {
+		total *= 0.90
+	}
+	if discount, ok := promoCodes[req.PromoCode]; ok {
+		total *= 1 - discount
+	}
+
+	booking := &models.Booking{
+		BookingRef:       bookingRef,
+		ScheduleID:       req.ScheduleID,
+		BookingDate:      req.Date,
+		Status:           "confirmed",
+		TotalPrice:       round2(total),
+		PassengerCount:   len(req.Passengers),
+		PromoCode:        req.PromoCode,
+		ContactEmail:     req.ContactEmail,
+		ContactPhone:     req.ContactPhone,
+		ConfirmationCode: confirmationCode,
+	}
+	err = tx.QueryRow(
+		`INSERT INTO bookings (booking_ref, schedule_id, booking_date, status, total_price, passenger_count, promo_code, contact_email, contact_phone, session_id, confirmation_code)
+		 VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11)
+		 RETURNING id, created_at`,
+		booking.BookingRef, booking.ScheduleID, booking.BookingDate, booking.Status,
+		booking.TotalPrice, booking.PassengerCount, booking.PromoCode, booking.ContactEmail,
+		booking.ContactPhone, req.SessionID, booking.ConfirmationCode,
+	).Scan(&booking.ID, &booking.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("inserting booking: %w", err)
+	}
+
+	// Seats are numbered from the count of passengers already booked on
+	// this schedule and date, six per carriage row.
+	var seatOffset int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM passengers p
+		 JOIN bookings b ON b.id = p.booking_id
+		 WHERE b.schedule_id = $1 AND b.booking_date = $2 AND b.status = 'confirmed'`,
+		req.ScheduleID, req.Date,
+	).Scan(&seatOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, p := range req.Passengers {
+		passenger := models.Passenger{
+			BookingID:         booking.ID,
+			Name:              p.Name,
+			PassengerType:     defaultPassengerType(p.PassengerType),
+			Price:             round2(prices[i]),
+			Email:             p.Email,
+			LoyaltyCardNumber: p.LoyaltyCardNumber,
+		}
+		if passenger.PassengerType != "infant" {
+			passenger.SeatNumber = seatNumber(seatOffset)
+			seatOffset++
+		}
+		err = tx.QueryRow(
+			`INSERT INTO passengers (booking_id, name, passenger_type, seat_number, price, email, loyalty_card_number)
+			 VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, ''), NULLIF($7, ''))
+			 RETURNING id`,
+			passenger.BookingID, passenger.Name, passenger.PassengerType,
+			passenger.SeatNumber, passenger.Price, passenger.Email,
+			passenger.LoyaltyCardNumber,
+		).Scan(&passenger.ID)
+		if err != nil {
+			return nil, fmt.Errorf("inserting passenger: %w", err)
+		}
+		booking.Passengers = append(booking.Passengers, passenger)
+	}
+
+	_, err = tx.Exec(
+		`UPDATE schedules SET available_seats = available_seats - $1 WHERE id = $2`,
+		seatsNeeded, req.ScheduleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordBookingEvent(tx, booking.BookingRef, "created", map[string]interface{}{
+		"schedule_id": req.ScheduleID, "date": req.Date, "total_price": booking.TotalPrice,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	SendBookingConfirmation(booking)
+	awardLoyaltyPoints(booking.BookingRef, booking.Passengers)
+	return booking, nil
+}
+
+// VerifyBookingCode checks a booking reference against its 4-digit PIN.
+// A wrong reference and a wrong code are indistinguishable to the caller.
+func VerifyBookingCode(bookingRef, code string) (bool, string, error) {
+	var status string
+	err := db.QueryRow(
+		`SELECT status FROM bookings WHERE booking_ref = $1 AND confirmation_code = $2`,
+		bookingRef, code,
+	).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return true, status, nil
+}
+
+// GetBooking loads a booking with its passengers and schedule details.
+func GetBooking(bookingRef string) (*models.Booking, error) {
+	var b models.Booking
+	err := db.QueryRow(
+		`SELECT b.id, b.booking_ref, b.schedule_id, to_char(b.booking_date, 'YYYY-MM-DD'),
+		        b.status, b.total_price, b.refund_amount, b.passenger_count,
+		        COALESCE(b.promo_code, ''), COALESCE(b.contact_email, ''), b.created_at,
+		        t.number, o.name, d.name,
+		        to_char(s.departure_time, 'HH24:MI'), to_char(s.arrival_time, 'HH24:MI')
+		 FROM bookings b
+		 JOIN schedules s ON s.id = b.schedule_id
+		 JOIN trains t ON t.id = s.train_id
+		 JOIN stations o ON o.id = s.origin_id
+		 JOIN stations d ON d.id = s.destination_id
+		 WHERE b.booking_ref = $1`,
+		bookingRef,
+	).Scan(
+		&b.ID, &b.BookingRef, &b.ScheduleID, &b.BookingDate, &b.Status,
+		&b.TotalPrice, &b.RefundAmount, &b.PassengerCount, &b.PromoCode, &b.ContactEmail, &b.CreatedAt,
+		&b.TrainNumber, &b.Origin, &b.Destination, &b.DepartureTime, &b.ArrivalTime,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("booking %s not found", bookingRef)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	passengers, err := getPassengers(b.ID)
+	if err != nil {
+		return nil, err
+	}
+	b.Passengers = passengers
+	return &b, nil
+}
+
+// GetRecentBookingsBySession returns the latest bookings created through
+// one chat session, newest first, so the assistant can refer back to them.
+func GetRecentBookingsBySession(sessionID string, limit int) ([]models.Booking, error) {
+	if limit < 1 || limit > 50 {
+		limit = 5
+	}
+	rows, err := db.Query(
+		`SELECT b.booking_ref FROM bookings b
+		 WHERE b.session_id = $1
+		 ORDER BY b.created_at DESC
+		 LIMIT $2`,
+		sessionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("loading recent bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []string
+	for rows.Next() {
+		var ref string
+		if err := rows.Scan(&ref); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	bookings := make([]models.Booking, 0, len(refs))
+	for _, ref := range refs {
+		b, err := GetBooking(ref)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, *b)
+	}
+	return bookings, nil
+}
+
+// CheckDuplicateBooking reports whether the session already holds a confirmed
+// booking for the same schedule and travel date, and if so which one. It
+// guards against users asking the AI to book the same journey twice.
+func CheckDuplicateBooking(sessionID string, scheduleID int, date string) (bool, string, error) {
+	if sessionID == "" {
+		return false, "", nil
+	}
+	var ref string
+	err := db.QueryRow(
+		`SELECT b.booking_ref FROM bookings b
+		 WHERE b.session_id = $1 AND b.schedule_id = $2 AND b.booking_date = $3
+		   AND b.status = 'confirmed'
+		 ORDER BY b.created_at DESC
+		 LIMIT 1`,
+		sessionID, scheduleID, date,
+	).Scan(&ref)
+	if err == sql.ErrNoRows {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("checking duplicate booking: %w", err)
+	}
+	return true, ref, nil
+}
+
+// CancelBooking cancels a booking and computes the refund based on how far
+// in advance the cancellation happens: 100% more than 24h before departure,
+// 50% between 24h and 6h, nothing after that.
+func CancelBooking(bookingRef string) (*models.Booking, error) {
+	booking, err := GetBooking(bookingRef)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status != "confirmed" {
+		return nil, fmt.Errorf("booking %s is %s, cannot cancel", bookingRef, booking.Status)
+	}
+
+	refundPct := refundPercentage(departureTimestamp(booking))
+	refund := round2(booking.TotalPrice * refundPct)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`UPDATE bookings SET status = 'cancelled', refund_amount = $1 WHERE booking_ref = $2`,
+		refund, bookingRef,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	seatsFreed := 0
+	for _, p := range booking.Passengers {
+		if p.PassengerType != "infant" {
+			seatsFreed++
+		}
+	}
+	_, err = tx.Exec(
+		`UPDATE schedules SET available_seats = available_seats + $1 WHERE id = $2`,
+		seatsFreed, booking.ScheduleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordBookingEvent(tx, bookingRef, "cancelled", map[string]interface{}{
+		"refund_amount": refund, "refund_pct": refundPct,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	booking.Status = "cancelled"
+	booking.RefundAmount = refund
+	return booking, nil
+}
+
+// GetBookingModificationCount counts how many times a booking has been
+// modified, based on its audit trail: date changes, passenger transfers and
+// passenger updates all count, cancellations and creations do not.
+func GetBookingModificationCount(bookingRef string) (int, error) {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM booking_events
+		 WHERE booking_ref = $1
+		   AND event_type IN ('date_changed', 'passenger_transfer', 'passenger_updated')`,
+		bookingRef,
+	).Scan(&count)
+	return count, err
+}
+
+// ChangeBookingDate moves a confirmed booking to another date on which the
+// same schedule operates.
+func ChangeBookingDate(bookingRef, newDate string) (*models.Booking, error) {
+	booking, err := GetBooking(bookingRef)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status != "confirmed" {
+		return nil, fmt.Errorf("booking %s is %s, cannot change date", bookingRef, booking.Status)
+	}
+
+	modifications, err := GetBookingModificationCount(bookingRef)
+	if err != nil {
+		return nil, err
+	}
+	if modifications >= cfg.MaxBookingModifications {
+		return nil, ErrModificationLimit
+	}
+
+	newTravelDate, err := time.Parse("2006-01-02", newDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: use YYYY-MM-DD", newDate)
+	}
+
+	var dayOfWeek int
+	err = db.QueryRow(
+		`SELECT day_of_week FROM schedules WHERE id = $1`, booking.ScheduleID,
+	).Scan(&dayOfWeek)
+	if err != nil {
+		return nil, err
+	}
+	if int(newTravelDate.Weekday()) != dayOfWeek {
+		return nil, fmt.Errorf("schedule %d does not operate on %s", booking.ScheduleID, newDate)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`UPDATE bookings SET booking_date = $1 WHERE booking_ref = $2`, newDate, bookingRef,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordBookingEvent(tx, bookingRef, "date_changed", map[string]interface{}{
+		"old_date": booking.BookingDate, "new_date": newDate,
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	booking.BookingDate = newDate
+	return booking, nil
+}
+
+// UpdatePassengers replaces the passenger names on a confirmed booking.
+// The passenger count and types must stay the same; this is a name-correction
+// facility, not a rebooking.
+func UpdatePassengers(bookingRef string, passengers []models.PassengerCreateRequest) (*models.Booking, error) {
+	normalisePassengerNames(passengers)
+	if err := validatePassengerNames(passengers); err != nil {
+		return nil, err
+	}
+
+	booking, err := GetBooking(bookingRef)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status != "confirmed" {
+		return nil, fmt.Errorf("booking %s is %s, cannot update passengers", bookingRef, booking.Status)
+	}
+	if len(passengers) != len(booking.Passengers) {
+		return nil, fmt.Errorf("passenger count mismatch: booking has %d, got %d",
+			len(booking.Passengers), len(passengers))
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for i, p := range passengers {
+		_, err = tx.Exec(
+			`UPDATE passengers SET name = $1 WHERE id = $2`,
+			p.Name, booking.Passengers[i].ID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		booking.Passengers[i].Name = p.Name
+	}
+
+	if err := recordBookingEvent(tx, bookingRef, "passenger_updated", nil); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// BulkCancelBookings cancels every confirmed booking on a schedule between
+// fromDate and toDate (toDate "" means open-ended), refunding in full. It
+// returns the number of bookings cancelled. Used when a service is
+// withdrawn.
+func BulkCancelBookings(scheduleID int, fromDate, toDate string, reason string) (int, error) {
+	rows, err := db.Query(
+		`SELECT booking_ref FROM bookings
+		 WHERE schedule_id = $1 AND booking_date >= $2 AND status = 'confirmed'
+		   AND ($3 = '' OR booking_date <= $3::date)`,
+		scheduleID, fromDate, toDate,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var refs []string
+	for rows.Next() {
+		var ref string
+		if err := rows.Scan(&ref); err != nil {
+			return 0, err
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for _, ref := range refs {
+		tx, err := db.Begin()
+		if err != nil {
+			return cancelled, err
+		}
+		_, err = tx.Exec(
+			`UPDATE bookings SET status = 'cancelled', refund_amount = total_price WHERE booking_ref = $1`,
+			ref,
+		)
+		if err == nil {
+			err = recordBookingEvent(tx, ref, "bulk_cancelled", map[string]interface{}{"reason": reason})
+		}
+		if err != nil {
+			tx.Rollback()
+			return cancelled, err
+		}
+		if err := tx.Commit(); err != nil {
+			return cancelled, err
+		}
+		cancelled++
+	}
+	return cancelled, nil
+}
+
+// SendBookingConfirmation "sends" the HTML confirmation to the booking's
+// contact email. The demo has no mail server, so only a summary line is
+// written to the log; the contact would receive the rendered email in a real
+// deployment.
+func SendBookingConfirmation(booking *models.Booking) {
+	recipient := booking.ContactEmail
+
+	body, err := renderConfirmationHTML(booking)
+	if err != nil {
+		log.Printf("rendering confirmation for %s: %v", booking.BookingRef, err)
+		return
+	}
+	if recipient == "" {
+		log.Printf("confirmation for %s (no email on file, %d bytes of HTML)", booking.BookingRef, len(body))
+		return
+	}
+	log.Printf("confirmation email to %s for %s (%d bytes of HTML)", recipient, booking.BookingRef, len(body))
+}
+
+func getPassengers(bookingID int) ([]models.Passenger, error) {
+	rows, err := db.Query(
+		`SELECT id, booking_id, name, passenger_type, COALESCE(seat_number, ''), price, COALESCE(email, ''), COALESCE(loyalty_card_number, '')
+		 FROM passengers WHERE booking_id = $1 ORDER BY id`,
+		bookingID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var passengers []models.Passenger
+	for rows.Next() {
+		var p models.Passenger
+		if err := rows.Scan(&p.ID, &p.BookingID, &p.Name, &p.PassengerType, &p.SeatNumber, &p.Price, &p.Email, &p.LoyaltyCardNumber); err != nil {
+			return nil, err
+		}
+		passengers = append(passengers, p)
+	}
+	return passengers, rows.Err()
+}
+
+// recordBookingEvent appends an audit row to booking_events inside tx.
+func recordBookingEvent(tx *sql.Tx, bookingRef, eventType string, details map[string]interface{}) error {
+	var payload interface{}
+	if details != nil {
+		b, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		payload = string(b)
+	}
+	_, err := tx.Exec(
+		`INSERT INTO booking_events (booking_ref, event_type, details) VALUES ($1, $2, $3)`,
+		bookingRef, eventType, payload,
+	)
+	return err
+}
+
+// departureTimestamp combines the booking date and departure time into a
+// single local timestamp.
+func departureTimestamp(b *models.Booking) time.Time {
+	ts, err := time.ParseInLocation("2006-01-02 15:04", b.BookingDate+" "+b.DepartureTime, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return ts
+}
+
+// refundPercentage implements the cancellation policy.
+func refundPercentage(departure time.Time) float64 {
+	until := time.Until(departure)
+	switch {
+	case until > 24*time.Hour:
+		return 1.0
+	case until > 6*time.Hour:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+func defaultPassengerType(t string) string {
+	if t == "" {
+		return "adult"
+	}
+	return t
+}
+
+func seatNumber(offset int) string {
+	return fmt.Sprintf("%d%c", offset/6+1, 'A'+offset%6)
+}
+
+func round2(v float64) float64 {
+	return float64(int(v*100+0.5)) / 100
+}
+
+// DuplicateBooking books the same schedule and passengers as an existing
+// confirmed booking on a new travel date — the "same trip next week" case.
+// Seat assignments are generated fresh; availability and operating-day
+// checks are the same as for any new booking.
+func DuplicateBooking(bookingRef, newDate string) (*models.Booking, error) {
+	original, err := GetBooking(bookingRef)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status != "confirmed" {
+		return nil, fmt.Errorf("booking %s is %s, only confirmed bookings can be duplicated",
+			bookingRef, original.Status)
+	}
+
+	passengers := make([]models.PassengerCreateRequest, 0, len(original.Passengers))
+	for _, p := range original.Passengers {
+		passengers = append(passengers, models.PassengerCreateRequest{
+			Name:          p.Name,
+			PassengerType: p.PassengerType,
+		})
+	}
+
+	return CreateBooking(models.BookingRequest{
+		ScheduleID:   original.ScheduleID,
+		Date:         newDate,
+		Passengers:   passengers,
+		ContactEmail: original.ContactEmail,
+	})
+}
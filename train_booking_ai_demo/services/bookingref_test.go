@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"trainbooking/models"
+)
+
+func TestGetBookingRefVersion(t *testing.T) {
+	cases := []struct {
+		ref  string
+		want string
+	}{
+		{"TRN-2025-00042", "v1"},
+		{"TRN-20251020-a1b2c3d4", "v2"},
+		{"TRN-2025-0042", ""},         // counter too short
+		{"TRN-20251020-A1B2C3D4", ""}, // v2 suffix is lowercase
+		{"not-a-ref", ""},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		if got := GetBookingRefVersion(tc.ref); got != tc.want {
+			t.Errorf("GetBookingRefVersion(%q) = %q, want %q", tc.ref, got, tc.want)
+		}
+	}
+}
+
+// TestV2BookingRefLifecycle creates and cancels a booking under the v2
+// reference format, whose references are one character longer than v1's:
+// a regression test for booking_events.booking_ref being too narrow to
+// record the "created" and "cancelled" events.
+func TestV2BookingRefLifecycle(t *testing.T) {
+	testDB := setupTestDB(t)
+	cfg.BookingRefFormat = "v2"
+
+	var scheduleID, dayOfWeek int
+	err := testDB.QueryRow(
+		`SELECT id, day_of_week FROM schedules
+		 WHERE is_active AND available_seats > 0 ORDER BY id LIMIT 1`,
+	).Scan(&scheduleID, &dayOfWeek)
+	if err != nil {
+		t.Fatalf("loading seed schedule: %v", err)
+	}
+	date := time.Now().AddDate(0, 0, 1)
+	for int(date.Weekday()) != dayOfWeek {
+		date = date.AddDate(0, 0, 1)
+	}
+
+	booking, err := CreateBooking(models.BookingRequest{
+		ScheduleID: scheduleID,
+		Date:       date.Format("2006-01-02"),
+		Passengers: []models.PassengerCreateRequest{{Name: "Ref Width Probe", PassengerType: "adult"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateBooking with v2 refs: %v", err)
+	}
+	t.Cleanup(func() {
+		testDB.Exec(`DELETE FROM booking_events WHERE booking_ref = $1`, booking.BookingRef)
+		testDB.Exec(`DELETE FROM bookings WHERE booking_ref = $1`, booking.BookingRef)
+	})
+	if got := GetBookingRefVersion(booking.BookingRef); got != "v2" {
+		t.Fatalf("booking ref %q parsed as %q, want v2", booking.BookingRef, got)
+	}
+
+	if _, err := CancelBooking(booking.BookingRef); err != nil {
+		t.Fatalf("CancelBooking(%s): %v", booking.BookingRef, err)
+	}
+}
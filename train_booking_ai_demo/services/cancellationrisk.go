@@ -0,0 +1,89 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// defaultCancellationWeights are hand-tuned logistic coefficients: bookings
+// made far in advance and large groups cancel more often.
+var defaultCancellationWeights = map[string]float64{
+	"intercept":   -2.5,
+	"lead_days":   0.06,
+	"passengers":  0.15,
+	"return_trip": 0.30,
+}
+
+// PredictBookingCancellationRisk scores how likely a confirmed booking is to
+// be cancelled, between 0.0 and 1.0. It is a heuristic for overbooking
+// management, not a trained model.
+func PredictBookingCancellationRisk(bookingRef string) (float64, error) {
+	var leadDays float64
+	var passengerCount int
+	var contactEmail string
+	var originID, destinationID int
+	err := db.QueryRow(`
+		SELECT EXTRACT(EPOCH FROM (b.booking_date::timestamp - b.created_at)) / 86400,
+		       (SELECT COUNT(*) FROM passengers p WHERE p.booking_id = b.id),
+		       COALESCE(b.contact_email, ''),
+		       s.origin_id, s.destination_id
+		FROM bookings b
+		JOIN schedules s ON s.id = b.schedule_id
+		WHERE b.booking_ref = $1`, bookingRef,
+	).Scan(&leadDays, &passengerCount, &contactEmail, &originID, &destinationID)
+	if err != nil {
+		return 0, fmt.Errorf("loading booking %s: %w", bookingRef, err)
+	}
+	if leadDays < 0 {
+		leadDays = 0
+	}
+
+	isReturn := false
+	if contactEmail != "" {
+		// A booking on the reverse route under the same contact email is
+		// treated as the return leg of a round trip.
+		err := db.QueryRow(`
+			SELECT EXISTS (
+				SELECT 1 FROM bookings b
+				JOIN schedules s ON s.id = b.schedule_id
+				WHERE b.contact_email = $1 AND b.status = 'confirmed'
+				  AND s.origin_id = $2 AND s.destination_id = $3
+			)`, contactEmail, destinationID, originID).Scan(&isReturn)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return scoreCancellationRisk(leadDays, passengerCount, isReturn, cancellationWeights()), nil
+}
+
+// cancellationWeights returns the configured coefficients, overlaying
+// CANCELLATION_MODEL_WEIGHTS onto the defaults key by key.
+func cancellationWeights() map[string]float64 {
+	weights := make(map[string]float64, len(defaultCancellationWeights))
+	for k, v := range defaultCancellationWeights {
+		weights[k] = v
+	}
+	if cfg == nil || cfg.CancellationModelWeights == "" {
+		return weights
+	}
+	var overrides map[string]float64
+	if err := json.Unmarshal([]byte(cfg.CancellationModelWeights), &overrides); err != nil {
+		return weights
+	}
+	for k, v := range overrides {
+		weights[k] = v
+	}
+	return weights
+}
+
+// scoreCancellationRisk applies the logistic function to the weighted
+// features, so the result is always in [0, 1].
+func scoreCancellationRisk(leadDays float64, passengerCount int, isReturn bool, w map[string]float64) float64 {
+	z := w["intercept"] + w["lead_days"]*leadDays + w["passengers"]*float64(passengerCount)
+	if isReturn {
+		z += w["return_trip"]
+	}
+	return 1 / (1 + math.Exp(-z))
+}
@@ -0,0 +1,30 @@
+package services
+
+import "testing"
+
+func TestScoreCancellationRiskBounds(t *testing.T) {
+	w := defaultCancellationWeights
+	for _, leadDays := range []float64{0, 1, 30, 365, 10000} {
+		for _, passengers := range []int{1, 5, 50} {
+			for _, isReturn := range []bool{false, true} {
+				score := scoreCancellationRisk(leadDays, passengers, isReturn, w)
+				if score < 0 || score > 1 {
+					t.Errorf("score(%v, %d, %v) = %v, outside [0,1]",
+						leadDays, passengers, isReturn, score)
+				}
+			}
+		}
+	}
+}
+
+func TestScoreCancellationRiskIncreasesWithLeadTime(t *testing.T) {
+	w := defaultCancellationWeights
+	previous := -1.0
+	for _, leadDays := range []float64{0, 7, 30, 90} {
+		score := scoreCancellationRisk(leadDays, 2, false, w)
+		if score <= previous {
+			t.Errorf("score at %v lead days = %v, not greater than %v", leadDays, score, previous)
+		}
+		previous = score
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// CapacityRecord aggregates offered vs booked seat-days for one schedule in
+// one bucket (a week or a single day). A seat-day is one seat on one
+// operating day.
+type CapacityRecord struct {
+	WeekStarting   string  `json:"week_starting"`
+	ScheduleID     int     `json:"schedule_id"`
+	TrainType      string  `json:"train_type"`
+	Route          string  `json:"route"`
+	TotalSeatDays  int     `json:"total_seat_days"`
+	BookedSeatDays int     `json:"booked_seat_days"`
+	UtilizationPct float64 `json:"utilization_pct"`
+}
+
+// GetScheduleCapacityReport computes seat-days offered and booked per active
+// schedule over [from, to), bucketed by ISO week (Monday start) or by day.
+func GetScheduleCapacityReport(from, to time.Time, groupBy string) ([]CapacityRecord, error) {
+	if groupBy != "week" && groupBy != "day" {
+		return nil, fmt.Errorf("group_by must be week or day")
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("to must be after from")
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		return nil, fmt.Errorf("range too large: max one year")
+	}
+
+	type scheduleInfo struct {
+		id         int
+		trainType  string
+		route      string
+		dayOfWeek  int
+		totalSeats int
+	}
+	rows, err := db.Query(
+		`SELECT s.id, t.type, o.name || ' - ' || d.name, s.day_of_week, s.total_seats
+		 FROM schedules s
+		 JOIN trains t ON t.id = s.train_id
+		 JOIN stations o ON o.id = s.origin_id
+		 JOIN stations d ON d.id = s.destination_id
+		 WHERE s.is_active
+		 ORDER BY s.id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []scheduleInfo
+	for rows.Next() {
+		var s scheduleInfo
+		if err := rows.Scan(&s.id, &s.trainType, &s.route, &s.dayOfWeek, &s.totalSeats); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Confirmed passenger counts per (schedule, date), one aggregate query.
+	booked := map[string]int{}
+	bookedRows, err := db.Query(
+		`SELECT b.schedule_id, to_char(b.booking_date, 'YYYY-MM-DD'), COUNT(p.id)
+		 FROM bookings b
+		 JOIN passengers p ON p.booking_id = b.id
+		 WHERE b.status = 'confirmed' AND b.booking_date >= $1 AND b.booking_date < $2
+		 GROUP BY b.schedule_id, b.booking_date`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer bookedRows.Close()
+	for bookedRows.Next() {
+		var scheduleID, count int
+		var date string
+		if err := bookedRows.Scan(&scheduleID, &date, &count); err != nil {
+			return nil, err
+		}
+		booked[fmt.Sprintf("%d/%s", scheduleID, date)] = count
+	}
+	if err := bookedRows.Err(); err != nil {
+		return nil, err
+	}
+
+	acc := map[string]*CapacityRecord{}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		bucket := day.Format("2006-01-02")
+		if groupBy == "week" {
+			bucket = weekStart(day).Format("2006-01-02")
+		}
+		for _, s := range schedules {
+			if int(day.Weekday()) != s.dayOfWeek {
+				continue
+			}
+			key := fmt.Sprintf("%s/%d", bucket, s.id)
+			rec, ok := acc[key]
+			if !ok {
+				rec = &CapacityRecord{
+					WeekStarting: bucket,
+					ScheduleID:   s.id,
+					TrainType:    s.trainType,
+					Route:        s.route,
+				}
+				acc[key] = rec
+			}
+			rec.TotalSeatDays += s.totalSeats
+			rec.BookedSeatDays += booked[fmt.Sprintf("%d/%s", s.id, day.Format("2006-01-02"))]
+		}
+	}
+
+	records := make([]CapacityRecord, 0, len(acc))
+	for _, rec := range acc {
+		if rec.TotalSeatDays > 0 {
+			rec.UtilizationPct = round2(float64(rec.BookedSeatDays) / float64(rec.TotalSeatDays) * 100)
+		}
+		records = append(records, *rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].WeekStarting != records[j].WeekStarting {
+			return records[i].WeekStarting < records[j].WeekStarting
+		}
+		return records[i].ScheduleID < records[j].ScheduleID
+	})
+	return records, nil
+}
+
+// weekStart returns the Monday of the week containing t.
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
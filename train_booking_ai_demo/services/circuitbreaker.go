@@ -0,0 +1,82 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// Circuit breaker states.
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+)
+
+// AICircuitBreaker stops calls to an AI provider after repeated failures:
+// Closed until the failure threshold is hit, then Open (calls rejected
+// immediately) until the timeout elapses, then Half-Open where one probe
+// call decides whether to close again.
+type AICircuitBreaker struct {
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+}
+
+// circuitBreakers holds one breaker per provider name.
+var circuitBreakers sync.Map
+
+// breakerFor returns (creating if needed) the breaker of a provider.
+func breakerFor(provider string) *AICircuitBreaker {
+	b, _ := circuitBreakers.LoadOrStore(provider, &AICircuitBreaker{state: breakerClosed})
+	return b.(*AICircuitBreaker)
+}
+
+// Allow reports whether a call may proceed, moving Open to Half-Open once
+// the configured timeout has elapsed.
+func (b *AICircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerOpen {
+		timeout := time.Duration(cfg.AICircuitBreakerTimeoutSeconds) * time.Second
+		if time.Since(b.openedAt) < timeout {
+			return false
+		}
+		b.state = breakerHalfOpen
+	}
+	return true
+}
+
+// RecordSuccess resets the breaker to Closed.
+func (b *AICircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = breakerClosed
+	b.failures = 0
+}
+
+// RecordFailure counts a failure and opens the breaker at the threshold.
+// A failure during Half-Open reopens immediately.
+func (b *AICircuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= cfg.AICircuitBreakerThreshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// State returns the current state name for observability.
+func (b *AICircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// CircuitBreakerState returns the breaker state of a provider, for /health.
+func CircuitBreakerState(provider string) string {
+	return breakerFor(provider).State()
+}
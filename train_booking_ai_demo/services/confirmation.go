@@ -0,0 +1,56 @@
+package services
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"trainbooking/models"
+)
+
+//go:embed templates/confirmation.html
+var confirmationTemplateHTML string
+
+var confirmationTemplate = template.Must(
+	template.New("confirmation").Parse(confirmationTemplateHTML))
+
+// confirmationRefundPolicy is the cancellation policy shown in the email,
+// matching refundPercentage.
+const confirmationRefundPolicy = "Cancellation: full refund more than 24 hours before departure, " +
+	"50% between 24 and 6 hours, no refund within 6 hours."
+
+// confirmationContactURL is the support link in the email footer.
+const confirmationContactURL = "https://trainbooking.example/support"
+
+// ConfirmationData is the input of the confirmation email template.
+type ConfirmationData struct {
+	Booking      *models.Booking
+	RefundPolicy string
+	ContactURL   string
+}
+
+// GetBookingConfirmationHTML renders a booking's confirmation email as HTML,
+// for sending and for the web preview endpoint.
+func GetBookingConfirmationHTML(bookingRef string) (string, error) {
+	booking, err := GetBooking(bookingRef)
+	if err != nil {
+		return "", err
+	}
+	return renderConfirmationHTML(booking)
+}
+
+// renderConfirmationHTML renders the confirmation template for an already
+// loaded booking.
+func renderConfirmationHTML(booking *models.Booking) (string, error) {
+	var b strings.Builder
+	err := confirmationTemplate.Execute(&b, ConfirmationData{
+		Booking:      booking,
+		RefundPolicy: confirmationRefundPolicy,
+		ContactURL:   confirmationContactURL,
+	})
+	if err != nil {
+		return "", fmt.Errorf("rendering confirmation for %s: %w", booking.BookingRef, err)
+	}
+	return b.String(), nil
+}
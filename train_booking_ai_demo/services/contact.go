@@ -0,0 +1,63 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ContactInfo is a booking's contact details, possibly masked.
+type ContactInfo struct {
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// GetBookingContactInfo returns a booking's contact details unmasked. Admin
+// use only; everyone else goes through GetBookingContactInfoMasked.
+func GetBookingContactInfo(bookingRef string) (ContactInfo, error) {
+	var info ContactInfo
+	err := db.QueryRow(
+		`SELECT COALESCE(contact_email, ''), COALESCE(contact_phone, '')
+		 FROM bookings WHERE booking_ref = $1`,
+		bookingRef,
+	).Scan(&info.Email, &info.Phone)
+	if err == sql.ErrNoRows {
+		return info, fmt.Errorf("booking %s not found", bookingRef)
+	}
+	return info, err
+}
+
+// GetBookingContactInfoMasked is the privacy-preserving variant: enough of
+// the email and phone remain for the owner to recognise them, not enough for
+// anyone else to use.
+func GetBookingContactInfoMasked(bookingRef string) (ContactInfo, error) {
+	info, err := GetBookingContactInfo(bookingRef)
+	if err != nil {
+		return info, err
+	}
+	info.Email = maskEmail(info.Email)
+	info.Phone = maskPhone(info.Phone)
+	return info, nil
+}
+
+// maskEmail keeps the first two characters of the local part and the full
+// domain: "mario.rossi@example.com" -> "ma***@example.com".
+func maskEmail(email string) string {
+	at := strings.Index(email, "@")
+	if at <= 0 {
+		return email
+	}
+	keep := 2
+	if at < keep {
+		keep = 1
+	}
+	return email[:keep] + "***" + email[at:]
+}
+
+// maskPhone replaces everything but the last four characters with "*".
+func maskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return phone
+	}
+	return strings.Repeat("*", len(phone)-4) + phone[len(phone)-4:]
+}
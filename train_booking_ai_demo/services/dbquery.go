@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxAdhocQueryRows caps the result size of ad-hoc admin queries.
+const maxAdhocQueryRows = 100
+
+// TestDatabaseQuery runs an ad-hoc read-only query for admins. Only SELECT
+// statements are accepted, the transaction is read-only, execution is capped
+// at 5 seconds and at most 100 rows are returned. Callers are responsible
+// for auditing who ran what.
+func TestDatabaseQuery(query string) ([]map[string]interface{}, error) {
+	trimmed := strings.TrimSpace(query)
+	if !strings.HasPrefix(strings.ToUpper(trimmed), "SELECT") {
+		return nil, fmt.Errorf("only SELECT queries are allowed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("starting read-only transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("running query: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []map[string]interface{}
+	for rows.Next() && len(results) < maxAdhocQueryRows {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			// lib/pq returns text columns as []byte; convert so the
+			// JSON encoding is a string, not base64.
+			if b, ok := values[i].([]byte); ok {
+				row[col] = string(b)
+			} else {
+				row[col] = values[i]
+			}
+		}
+		results = append(results, row)
+	}
+	return results, rows.Err()
+}
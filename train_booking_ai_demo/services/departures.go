@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// DepartureInfo is one row of the simulated live departure board.
+type DepartureInfo struct {
+	ScheduleID    int    `json:"schedule_id"`
+	TrainNumber   string `json:"train_number"`
+	Destination   string `json:"destination"`
+	DepartureTime string `json:"departure_time"`
+	Platform      string `json:"platform"`
+	Status        string `json:"status"`
+	DelayMinutes  int    `json:"delay_minutes"`
+}
+
+// GetLiveDepartures returns the departures from a station within the next
+// windowMinutes. This is a demo, so status and delay are simulated: they are
+// generated deterministically from the schedule id and today's date, so the
+// board is stable within a day but varies between days.
+func GetLiveDepartures(stationCode string, windowMinutes int) ([]DepartureInfo, error) {
+	station, err := GetStationByCode(stationCode)
+	if err != nil {
+		return nil, err
+	}
+	if windowMinutes <= 0 {
+		windowMinutes = 60
+	}
+
+	now := time.Now()
+	windowEnd := now.Add(time.Duration(windowMinutes) * time.Minute)
+
+	rows, err := db.Query(
+		`SELECT s.id, t.number, d.name, to_char(s.departure_time, 'HH24:MI')
+		 FROM schedules s
+		 JOIN trains t ON t.id = s.train_id
+		 JOIN stations d ON d.id = s.destination_id
+		 WHERE s.origin_id = $1 AND s.day_of_week = $2 AND s.is_active
+		   AND s.departure_time BETWEEN $3 AND $4
+		 ORDER BY s.departure_time`,
+		station.ID, int(now.Weekday()), now.Format("15:04"), windowEnd.Format("15:04"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying departures: %w", err)
+	}
+	defer rows.Close()
+
+	var departures []DepartureInfo
+	for rows.Next() {
+		var d DepartureInfo
+		if err := rows.Scan(&d.ScheduleID, &d.TrainNumber, &d.Destination, &d.DepartureTime); err != nil {
+			return nil, err
+		}
+		d.Platform = strconv.Itoa(d.ScheduleID%10 + 1)
+		d.Status, d.DelayMinutes = simulateDelay(d.ScheduleID, now)
+		departures = append(departures, d)
+	}
+	return departures, rows.Err()
+}
+
+// simulateDelay derives a plausible status from a seed that is constant for
+// one schedule within one day: roughly 70% on time, a rare cancellation and
+// delays of 5 to 35 minutes otherwise.
+func simulateDelay(scheduleID int, day time.Time) (string, int) {
+	seed, _ := strconv.Atoi(day.Format("20060102"))
+	rng := rand.New(rand.NewSource(int64(scheduleID + seed)))
+
+	roll := rng.Intn(100)
+	switch {
+	case roll < 70:
+		return "on_time", 0
+	case roll < 95:
+		return "delayed", 5 + rng.Intn(31)
+	default:
+		return "cancelled", 0
+	}
+}
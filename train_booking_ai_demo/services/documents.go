@@ -0,0 +1,39 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// AttachBookingDocuments records the stored document paths on a booking,
+// in passenger order.
+func AttachBookingDocuments(bookingRef string, paths []string) error {
+	result, err := db.Exec(
+		`UPDATE bookings SET documents = $1 WHERE booking_ref = $2`,
+		pq.Array(paths), bookingRef)
+	if err != nil {
+		return fmt.Errorf("attaching documents to %s: %w", bookingRef, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("booking %s not found", bookingRef)
+	}
+	return nil
+}
+
+// GetBookingDocumentPath returns the stored path of one document of a
+// booking, by zero-based passenger index.
+func GetBookingDocumentPath(bookingRef string, index int) (string, error) {
+	var paths []string
+	err := db.QueryRow(
+		`SELECT COALESCE(documents, '{}') FROM bookings WHERE booking_ref = $1`,
+		bookingRef,
+	).Scan(pq.Array(&paths))
+	if err != nil {
+		return "", fmt.Errorf("loading booking %s: %w", bookingRef, err)
+	}
+	if index < 0 || index >= len(paths) {
+		return "", fmt.Errorf("booking %s has no document %d", bookingRef, index)
+	}
+	return paths[index], nil
+}
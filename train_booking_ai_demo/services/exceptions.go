@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"time"
+)
+
+// AddScheduleException marks one date on which a schedule deviates from its
+// weekly pattern. exceptionType is "cancelled" (the service does not run and
+// is hidden from search) or "modified" (informational). Re-adding the same
+// date replaces the existing exception.
+func AddScheduleException(scheduleID int, date string, exceptionType string, reason string) error {
+	if exceptionType != "cancelled" && exceptionType != "modified" {
+		return fmt.Errorf("invalid exception_type %q: must be cancelled or modified", exceptionType)
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return fmt.Errorf("invalid date %q: use YYYY-MM-DD", date)
+	}
+	if exists, err := rowExists("schedules", scheduleID); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("schedule %d not found", scheduleID)
+	}
+
+	_, err := db.Exec(
+		`INSERT INTO schedule_exceptions (schedule_id, exception_date, exception_type, reason)
+		 VALUES ($1, $2, $3, NULLIF($4, ''))
+		 ON CONFLICT (schedule_id, exception_date)
+		 DO UPDATE SET exception_type = EXCLUDED.exception_type, reason = EXCLUDED.reason`,
+		scheduleID, date, exceptionType, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("adding exception for schedule %d on %s: %w", scheduleID, date, err)
+	}
+	return nil
+}
+
+// RemoveScheduleException deletes the exception for one date, restoring the
+// schedule's normal operation.
+func RemoveScheduleException(scheduleID int, date string) error {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return fmt.Errorf("invalid date %q: use YYYY-MM-DD", date)
+	}
+	result, err := db.Exec(
+		`DELETE FROM schedule_exceptions WHERE schedule_id = $1 AND exception_date = $2`,
+		scheduleID, date,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("no exception for schedule %d on %s", scheduleID, date)
+	}
+	return nil
+}
+
+// cancelledExceptionDates returns the dates in a month on which a schedule
+// is exceptionally cancelled.
+func cancelledExceptionDates(scheduleID, month, year int) (map[string]bool, error) {
+	rows, err := db.Query(
+		`SELECT to_char(exception_date, 'YYYY-MM-DD') FROM schedule_exceptions
+		 WHERE schedule_id = $1 AND exception_type = 'cancelled'
+		   AND date_part('month', exception_date) = $2
+		   AND date_part('year', exception_date) = $3`,
+		scheduleID, month, year,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cancelled := map[string]bool{}
+	for rows.Next() {
+		var date string
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		cancelled[date] = true
+	}
+	return cancelled, rows.Err()
+}
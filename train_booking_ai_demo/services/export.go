@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// exportColumns is the CSV header of ExportBookingsCSV, one row per
+// passenger.
+var exportColumns = []string{
+	"booking_ref", "status", "booking_date", "created_at",
+	"train_number", "train_type", "origin", "destination", "departure_time",
+	"passenger_name", "passenger_type", "seat_number", "passenger_price", "total_price",
+}
+
+// ExportBookingsCSV streams all bookings created in [from, to) to w as CSV,
+// one row per passenger, and returns the number of data rows written. status
+// filters on the booking status when non-empty. Rows are streamed straight
+// from the cursor so arbitrarily large exports stay flat in memory.
+func ExportBookingsCSV(from, to time.Time, status string, w io.Writer) (int, error) {
+	rows, err := db.QueryContext(context.Background(),
+		`SELECT b.booking_ref, b.status, b.booking_date, b.created_at,
+		        t.number, t.type, o.name, d.name, to_char(s.departure_time, 'HH24:MI'),
+		        p.name, p.passenger_type, COALESCE(p.seat_number, ''), p.price, b.total_price
+		 FROM bookings b
+		 JOIN passengers p ON p.booking_id = b.id
+		 JOIN schedules s ON s.id = b.schedule_id
+		 JOIN trains t ON t.id = s.train_id
+		 JOIN stations o ON o.id = s.origin_id
+		 JOIN stations d ON d.id = s.destination_id
+		 WHERE b.created_at >= $1 AND b.created_at < $2
+		   AND ($3 = '' OR b.status = $3)
+		 ORDER BY b.created_at, b.booking_ref, p.id`,
+		from, to, status,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportColumns); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for rows.Next() {
+		var (
+			ref, bookingStatus, trainNumber, trainType string
+			origin, destination, departureTime         string
+			passengerName, passengerType, seatNumber   string
+			bookingDate                                time.Time
+			createdAt                                  time.Time
+			passengerPrice, totalPrice                 float64
+		)
+		err := rows.Scan(&ref, &bookingStatus, &bookingDate, &createdAt,
+			&trainNumber, &trainType, &origin, &destination, &departureTime,
+			&passengerName, &passengerType, &seatNumber, &passengerPrice, &totalPrice)
+		if err != nil {
+			return count, err
+		}
+		record := []string{
+			ref, bookingStatus, bookingDate.Format("2006-01-02"), createdAt.Format(time.RFC3339),
+			trainNumber, trainType, origin, destination, departureTime,
+			passengerName, passengerType, seatNumber,
+			strconv.FormatFloat(passengerPrice, 'f', 2, 64), strconv.FormatFloat(totalPrice, 'f', 2, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	cw.Flush()
+	return count, cw.Error()
+}
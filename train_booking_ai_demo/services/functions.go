@@ -0,0 +1,498 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"trainbooking/models"
+)
+
+// FunctionDefinition describes one function the AI can call, with its
+// parameters as a JSON schema.
+type FunctionDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// getFunctionDefinitions returns the functions exposed to the AI provider.
+func getFunctionDefinitions() []FunctionDefinition {
+	passengersSchema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"name":           map[string]interface{}{"type": "string"},
+				"passenger_type": map[string]interface{}{"type": "string", "enum": passengerTypeCodes()},
+			},
+			"required": []string{"name"},
+		},
+	}
+
+	return []FunctionDefinition{
+		{
+			Name:        "search_trains",
+			Description: "Search direct trains between two stations on a date. Results may include service alerts (engineering works, disruptions) to relay to the user.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"origin":          map[string]interface{}{"type": "string", "description": "Station code, name or city"},
+					"destination":     map[string]interface{}{"type": "string", "description": "Station code, name or city"},
+					"date":            map[string]interface{}{"type": "string", "description": "Travel date YYYY-MM-DD"},
+					"passenger_count": map[string]interface{}{"type": "integer"},
+					"time_preference": map[string]interface{}{"type": "string", "enum": []string{"morning", "afternoon", "evening"}},
+				},
+				"required": []string{"origin", "destination", "date"},
+			},
+		},
+		{
+			Name:        "create_booking",
+			Description: "Book a specific schedule (from a previous search) for a date and list of passengers.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"schedule_id": map[string]interface{}{"type": "integer"},
+					"date":        map[string]interface{}{"type": "string"},
+					"passengers":  passengersSchema,
+				},
+				"required": []string{"schedule_id", "date", "passengers"},
+			},
+		},
+		{
+			Name:        "book_train_direct",
+			Description: "Search and book the first suitable train in one step, when the user has already confirmed route, date and passengers.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"origin":      map[string]interface{}{"type": "string"},
+					"destination": map[string]interface{}{"type": "string"},
+					"date":        map[string]interface{}{"type": "string"},
+					"passengers":  passengersSchema,
+				},
+				"required": []string{"origin", "destination", "date", "passengers"},
+			},
+		},
+		{
+			Name:        "is_available",
+			Description: "Quickly check whether any train runs between two stations on a date, with count and price range, without listing every train.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"origin":      map[string]interface{}{"type": "string", "description": "Station code, name or city"},
+					"destination": map[string]interface{}{"type": "string", "description": "Station code, name or city"},
+					"date":        map[string]interface{}{"type": "string", "description": "Travel date YYYY-MM-DD"},
+				},
+				"required": []string{"origin", "destination", "date"},
+			},
+		},
+		{
+			Name:        "search_trains_by_arrival",
+			Description: "Search trains that arrive at the destination by a deadline, for users who say when they need to be there rather than when to leave.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"destination":     map[string]interface{}{"type": "string", "description": "Station code, name or city"},
+					"arrival_before":  map[string]interface{}{"type": "string", "description": "Latest acceptable arrival HH:MM"},
+					"date":            map[string]interface{}{"type": "string", "description": "Travel date YYYY-MM-DD"},
+					"origin":          map[string]interface{}{"type": "string", "description": "Station code, name or city; omit to search from anywhere"},
+					"passenger_count": map[string]interface{}{"type": "integer"},
+				},
+				"required": []string{"destination", "arrival_before", "date"},
+			},
+		},
+		{
+			Name:        "find_next_departure",
+			Description: "Find the very next departure with free seats between two stations, e.g. for a user who just missed a train. Looks up to 7 days ahead.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"origin":      map[string]interface{}{"type": "string", "description": "Station code, name or city"},
+					"destination": map[string]interface{}{"type": "string", "description": "Station code, name or city"},
+				},
+				"required": []string{"origin", "destination"},
+			},
+		},
+		{
+			Name:        "find_nearby_stations",
+			Description: "List stations within a radius of a station, with distances in km.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"station":   map[string]interface{}{"type": "string", "description": "Station code, name or city"},
+					"radius_km": map[string]interface{}{"type": "number"},
+				},
+				"required": []string{"station"},
+			},
+		},
+		{
+			Name:        "get_booking",
+			Description: "Look up an existing booking by its reference (TRN-...).",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"booking_ref": map[string]interface{}{"type": "string"},
+				},
+				"required": []string{"booking_ref"},
+			},
+		},
+		{
+			Name:        "get_recent_bookings",
+			Description: "List the latest bookings made in this chat session, newest first.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"limit": map[string]interface{}{"type": "integer", "description": "How many bookings, default 5"},
+				},
+			},
+		},
+		{
+			Name:        "get_train_delay_stats",
+			Description: "Punctuality statistics for a train: average delay, on-time percentage and the most delayed weekday.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"train_number": map[string]interface{}{"type": "string", "description": "Train number, e.g. FR9521"},
+					"months":       map[string]interface{}{"type": "integer", "description": "How many months of history, default 6"},
+				},
+				"required": []string{"train_number"},
+			},
+		},
+		{
+			Name:        "list_passenger_types",
+			Description: "List the passenger types with their discounts and example prices.",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "check_loyalty_points",
+			Description: "Check the points balance and tier of a loyalty card.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"card_number": map[string]interface{}{"type": "string"},
+				},
+				"required": []string{"card_number"},
+			},
+		},
+		{
+			Name:        "cancel_booking",
+			Description: "Cancel a booking by its reference. Refund depends on how close to departure.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"booking_ref": map[string]interface{}{"type": "string"},
+				},
+				"required": []string{"booking_ref"},
+			},
+		},
+		{
+			Name:        "get_modification_window",
+			Description: "Check until when a booking can still be cancelled or changed (travel date, passenger names) and what refund a cancellation right now would earn.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"booking_ref": map[string]interface{}{"type": "string"},
+				},
+				"required": []string{"booking_ref"},
+			},
+		},
+	}
+}
+
+// executeFunction dispatches an AI function call to its implementation and
+// returns a textual result to feed back to the model. Execution errors are
+// returned as text as well, so the AI can relay them to the user. sessionID
+// links bookings created here back to the chat session.
+func executeFunction(sessionID, name string, args map[string]interface{}) string {
+	var result interface{}
+	var err error
+
+	switch name {
+	case "search_trains":
+		result, err = executeSearchTrains(args)
+	case "create_booking":
+		result, err = executeCreateBooking(sessionID, args)
+	case "book_train_direct":
+		result, err = executeBookTrainDirect(sessionID, args)
+	case "is_available":
+		result, err = CheckAvailability(
+			argString(args, "origin"), argString(args, "destination"), argString(args, "date"))
+	case "search_trains_by_arrival":
+		result, err = SearchTrainsByArrival(
+			argString(args, "origin"), argString(args, "destination"),
+			argString(args, "arrival_before"), argString(args, "date"),
+			argInt(args, "passenger_count"))
+	case "find_next_departure":
+		result, err = FindNextAvailableDeparture(
+			argString(args, "origin"), argString(args, "destination"), time.Now())
+	case "find_nearby_stations":
+		result, err = executeFindNearbyStations(args)
+	case "get_booking":
+		result, err = GetBooking(argString(args, "booking_ref"))
+	case "cancel_booking":
+		result, err = CancelBooking(argString(args, "booking_ref"))
+	case "get_modification_window":
+		result, err = GetBookingModificationWindow(argString(args, "booking_ref"))
+	case "get_recent_bookings":
+		result, err = GetRecentBookingsBySession(sessionID, argInt(args, "limit"))
+	case "get_train_delay_stats":
+		result, err = GetTrainDelayStatistics(argString(args, "train_number"), argInt(args, "months"))
+	case "list_passenger_types":
+		result = GetPassengerTypePricing()
+	case "check_loyalty_points":
+		result, err = GetLoyaltyCard(argString(args, "card_number"))
+	default:
+		err = fmt.Errorf("unknown function %q", name)
+	}
+
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	return formatFunctionResult(name, result)
+}
+
+func executeSearchTrains(args map[string]interface{}) ([]models.SearchResponse, error) {
+	return SearchTrains(models.SearchRequest{
+		Origin:         argString(args, "origin"),
+		Destination:    argString(args, "destination"),
+		Date:           argString(args, "date"),
+		PassengerCount: argInt(args, "passenger_count"),
+		TimePreference: argString(args, "time_preference"),
+	})
+}
+
+func executeCreateBooking(sessionID string, args map[string]interface{}) (interface{}, error) {
+	return bookOnce(models.BookingRequest{
+		ScheduleID: argInt(args, "schedule_id"),
+		Date:       argString(args, "date"),
+		Passengers: fillMissingPassengerTypes(argPassengers(args)),
+		SessionID:  sessionID,
+	})
+}
+
+func executeBookTrainDirect(sessionID string, args map[string]interface{}) (interface{}, error) {
+	passengers := fillMissingPassengerTypes(argPassengers(args))
+	results, err := SearchTrains(models.SearchRequest{
+		Origin:         argString(args, "origin"),
+		Destination:    argString(args, "destination"),
+		Date:           argString(args, "date"),
+		PassengerCount: len(passengers),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no trains available for that route and date")
+	}
+	return bookOnce(models.BookingRequest{
+		ScheduleID: results[0].ScheduleID,
+		Date:       argString(args, "date"),
+		Passengers: passengers,
+		SessionID:  sessionID,
+	})
+}
+
+// duplicateBooking wraps an existing booking returned instead of creating a
+// second one for the same session, schedule and date, so the result
+// formatter can tell the user why nothing new was booked.
+type duplicateBooking struct {
+	Booking *models.Booking
+}
+
+// bookOnce creates a booking unless the session already holds a confirmed
+// booking for the same schedule and date, in which case the existing booking
+// is returned instead of double-charging the user.
+func bookOnce(req models.BookingRequest) (interface{}, error) {
+	dup, ref, err := CheckDuplicateBooking(req.SessionID, req.ScheduleID, req.Date)
+	if err != nil {
+		return nil, err
+	}
+	if dup {
+		existing, err := GetBooking(ref)
+		if err != nil {
+			return nil, err
+		}
+		return &duplicateBooking{Booking: existing}, nil
+	}
+	return CreateBooking(req)
+}
+
+func executeFindNearbyStations(args map[string]interface{}) ([]StationDistance, error) {
+	station, err := FindStationByNameOrCode(argString(args, "station"))
+	if err != nil {
+		return nil, err
+	}
+	radius := 50.0
+	if r, ok := args["radius_km"].(float64); ok && r > 0 {
+		radius = r
+	}
+	return GetNearbyStations(station.Code, radius)
+}
+
+// formatFunctionResult renders a function result as compact text for the AI.
+func formatFunctionResult(name string, result interface{}) string {
+	switch v := result.(type) {
+	case []models.SearchResponse:
+		if len(v) == 0 {
+			return "No trains found."
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d trains found:\n", len(v))
+		for _, r := range v {
+			fmt.Fprintf(&b, "- schedule %d: %s (%s) %s %s -> %s %s, %s, EUR %.2f/adult, %d seats left\n",
+				r.ScheduleID, r.TrainNumber, r.TrainType, r.Origin, r.DepartureTime,
+				r.Destination, r.ArrivalTime, r.Duration, r.PricePerAdult, r.AvailableSeats)
+			for _, a := range r.Alerts {
+				fmt.Fprintf(&b, "  ALERT (%s): %s\n", a.Severity, a.Message)
+			}
+		}
+		return b.String()
+	case *models.SearchResponse:
+		var b strings.Builder
+		fmt.Fprintf(&b, "Next departure: schedule %d, %s (%s) %s %s -> %s %s on %s, %s, EUR %.2f/adult, %d seats left.\n",
+			v.ScheduleID, v.TrainNumber, v.TrainType, v.Origin, v.DepartureTime,
+			v.Destination, v.ArrivalTime, v.Date, v.Duration, v.PricePerAdult, v.AvailableSeats)
+		for _, a := range v.Alerts {
+			fmt.Fprintf(&b, "ALERT (%s): %s\n", a.Severity, a.Message)
+		}
+		return b.String()
+	case *AvailabilitySummary:
+		if !v.Available {
+			return "No trains with available seats on that route and date."
+		}
+		return fmt.Sprintf("%d trains, departures %s-%s, from EUR %.2f/adult.",
+			v.TrainCount, v.EarliestDeparture, v.LatestDeparture, v.LowestPrice)
+	case ModificationWindow:
+		var b strings.Builder
+		if v.CanCancel {
+			fmt.Fprintf(&b, "Cancellation: open until departure %s, refund right now %.0f%%.\n",
+				v.CancelDeadline.Format("2006-01-02 15:04"), v.CancelRefundPct)
+		} else {
+			b.WriteString("Cancellation: no longer possible.\n")
+		}
+		if v.CanModifyDate {
+			fmt.Fprintf(&b, "Date change: open until %s.\n", v.ModifyDateDeadline.Format("2006-01-02 15:04"))
+		} else {
+			b.WriteString("Date change: no longer possible.\n")
+		}
+		if v.CanModifyPassenger {
+			fmt.Fprintf(&b, "Passenger name change: open until %s.\n", v.ModifyPassengerDeadline.Format("2006-01-02 15:04"))
+		} else {
+			b.WriteString("Passenger name change: no longer possible.\n")
+		}
+		return b.String()
+	case DelayStats:
+		return fmt.Sprintf(
+			"Train %s over %d departures: avg delay %.1f min, 95th percentile %.1f min, %.0f%% on time (within 5 min). Most delayed day: %s.",
+			v.TrainNumber, v.SampleSize, v.AvgDelayMinutes, v.P95DelayMinutes, v.OnTimePct, v.MostDelayedDayOfWeek)
+	case []PassengerTypePricing:
+		var b strings.Builder
+		fmt.Fprintf(&b, "Passenger types (example prices for a EUR %.2f adult fare):\n", examplePassengerBasePrice)
+		for _, p := range v {
+			switch {
+			case p.DiscountPct == 0:
+				fmt.Fprintf(&b, "- %s (%s): full price, EUR %.2f\n", p.Type, p.Description, p.ExamplePrice)
+			case p.DiscountPct == 100:
+				fmt.Fprintf(&b, "- %s (%s): free\n", p.Type, p.Description)
+			default:
+				fmt.Fprintf(&b, "- %s (%s): %.0f%% off, EUR %.2f\n", p.Type, p.Description, p.DiscountPct, p.ExamplePrice)
+			}
+		}
+		return b.String()
+	case []StationDistance:
+		if len(v) == 0 {
+			return "No stations within that radius."
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d stations nearby:\n", len(v))
+		for _, s := range v {
+			fmt.Fprintf(&b, "- %s (%s), %.1f km\n", s.Name, s.Code, s.DistanceKm)
+		}
+		return b.String()
+	case *LoyaltyCard:
+		return fmt.Sprintf("Card %s (%s): %d points, %s tier.",
+			v.CardNumber, v.PassengerName, v.PointsBalance, v.Tier)
+	case []models.Booking:
+		if len(v) == 0 {
+			return "No bookings in this session yet."
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d recent bookings:\n", len(v))
+		for _, booking := range v {
+			fmt.Fprintf(&b, "- %s (%s): %s -> %s on %s, %d passengers, EUR %.2f\n",
+				booking.BookingRef, booking.Status, booking.Origin, booking.Destination,
+				booking.BookingDate, booking.PassengerCount, booking.TotalPrice)
+		}
+		return b.String()
+	case *duplicateBooking:
+		return "Note: this session already booked this train and date, so no new booking was created. The existing booking is:\n" +
+			formatFunctionResult("get_booking", v.Booking)
+	case *models.Booking:
+		if name == "cancel_booking" {
+			return fmt.Sprintf("Booking %s cancelled. Refund: EUR %.2f.", v.BookingRef, v.RefundAmount)
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "Booking %s (%s): %s -> %s on %s at %s, total EUR %.2f.\n",
+			v.BookingRef, v.Status, v.Origin, v.Destination, v.BookingDate, v.DepartureTime, v.TotalPrice)
+		for _, p := range v.Passengers {
+			seat := p.SeatNumber
+			if seat == "" {
+				seat = "no seat"
+			}
+			fmt.Fprintf(&b, "- %s (%s), seat %s, EUR %.2f\n", p.Name, p.PassengerType, seat, p.Price)
+		}
+		return b.String()
+	default:
+		return fmt.Sprintf("%v", result)
+	}
+}
+
+func argString(args map[string]interface{}, key string) string {
+	if v, ok := args[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func argInt(args map[string]interface{}, key string) int {
+	switch v := args[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// fillMissingPassengerTypes resolves passengers the AI passed without a
+// usable type, using SuggestPassengerType.
+func fillMissingPassengerTypes(passengers []models.PassengerCreateRequest) []models.PassengerCreateRequest {
+	for i, p := range passengers {
+		if p.PassengerType == "" || p.PassengerType == "unknown" {
+			passengers[i].PassengerType = SuggestPassengerType(p.Name, nil)
+		}
+	}
+	return passengers
+}
+
+func argPassengers(args map[string]interface{}) []models.PassengerCreateRequest {
+	raw, ok := args["passengers"].([]interface{})
+	if !ok {
+		return nil
+	}
+	var passengers []models.PassengerCreateRequest
+	for _, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		passengers = append(passengers, models.PassengerCreateRequest{
+			Name:          argString(m, "name"),
+			PassengerType: argString(m, "passenger_type"),
+		})
+	}
+	return passengers
+}
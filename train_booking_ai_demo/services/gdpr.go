@@ -0,0 +1,111 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AnonymizeExpiredSessions redacts personal data from conversation history
+// of sessions whose last activity is older than olderThan: message text
+// becomes "[REDACTED]" and passenger names inside stored function calls are
+// nullified. Returns the number of anonymised rows.
+func AnonymizeExpiredSessions(olderThan time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-olderThan)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Redact passenger names first, while the rows are still identifiable.
+	rows, err := tx.Query(`
+		SELECT id, function_call FROM conversation_history
+		WHERE NOT is_anonymized AND function_call IS NOT NULL
+		  AND session_id IN (
+			SELECT session_id FROM conversation_history
+			GROUP BY session_id HAVING MAX(timestamp) < $1
+		  )`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("loading function calls: %w", err)
+	}
+
+	type redaction struct {
+		id      int
+		payload string
+	}
+	var redactions []redaction
+	for rows.Next() {
+		var id int
+		var raw []byte
+		if err := rows.Scan(&id, &raw); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		redacted, changed := redactFunctionCallNames(raw)
+		if changed {
+			redactions = append(redactions, redaction{id, redacted})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, err
+	}
+	rows.Close()
+
+	for _, r := range redactions {
+		if _, err := tx.Exec(
+			`UPDATE conversation_history SET function_call = $1 WHERE id = $2`,
+			r.payload, r.id); err != nil {
+			return 0, err
+		}
+	}
+
+	result, err := tx.Exec(`
+		UPDATE conversation_history
+		SET message = '[REDACTED]', is_anonymized = TRUE
+		WHERE NOT is_anonymized AND session_id IN (
+			SELECT session_id FROM conversation_history
+			GROUP BY session_id HAVING MAX(timestamp) < $1
+		)`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("anonymising sessions: %w", err)
+	}
+	count, _ := result.RowsAffected()
+	return count, tx.Commit()
+}
+
+// redactFunctionCallNames nullifies the name field of every passenger in a
+// stored function call payload. It reports whether anything changed; payloads
+// it cannot parse are left untouched.
+func redactFunctionCallNames(raw []byte) (string, bool) {
+	var call map[string]interface{}
+	if err := json.Unmarshal(raw, &call); err != nil {
+		return "", false
+	}
+	args, ok := call["arguments"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	passengers, ok := args["passengers"].([]interface{})
+	if !ok {
+		return "", false
+	}
+
+	changed := false
+	for _, item := range passengers {
+		if p, ok := item.(map[string]interface{}); ok && p["name"] != nil {
+			p["name"] = nil
+			changed = true
+		}
+	}
+	if !changed {
+		return "", false
+	}
+	redacted, err := json.Marshal(call)
+	if err != nil {
+		return "", false
+	}
+	return string(redacted), true
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+
+	"trainbooking/models"
+)
+
+// StationDistance is a station annotated with its distance from a
+// reference station.
+type StationDistance struct {
+	models.Station
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// GetNearbyStations returns the stations within radiusKm of the given
+// station, closest first. Stations without coordinates are skipped.
+func GetNearbyStations(code string, radiusKm float64) ([]StationDistance, error) {
+	center, err := GetStationByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if center.Latitude == 0 && center.Longitude == 0 {
+		return nil, fmt.Errorf("station %s has no coordinates", center.Code)
+	}
+
+	stations, err := GetAllStations()
+	if err != nil {
+		return nil, err
+	}
+
+	var nearby []StationDistance
+	for _, s := range stations {
+		if s.ID == center.ID || (s.Latitude == 0 && s.Longitude == 0) {
+			continue
+		}
+		d := haversineKm(center.Latitude, center.Longitude, s.Latitude, s.Longitude)
+		if d <= radiusKm {
+			nearby = append(nearby, StationDistance{Station: s, DistanceKm: round2(d)})
+		}
+	}
+
+	sort.Slice(nearby, func(i, j int) bool { return nearby[i].DistanceKm < nearby[j].DistanceKm })
+	return nearby, nil
+}
+
+// perKmRates are the approximate fares per kilometre by train type, matching
+// the figures quoted in the assistant's system prompt.
+var perKmRates = map[string]float64{
+	"FR": 0.15,
+	"IC": 0.10,
+	"RG": 0.06,
+}
+
+// ComputeRouteDistance returns the great-circle distance in kilometres
+// between two stations, looked up by code. Both stations must have
+// coordinates.
+func ComputeRouteDistance(originCode, destinationCode string) (float64, error) {
+	origin, err := GetStationByCode(originCode)
+	if err != nil {
+		return 0, err
+	}
+	destination, err := GetStationByCode(destinationCode)
+	if err != nil {
+		return 0, err
+	}
+	if origin.Latitude == 0 && origin.Longitude == 0 {
+		return 0, fmt.Errorf("station %s has no coordinates", origin.Code)
+	}
+	if destination.Latitude == 0 && destination.Longitude == 0 {
+		return 0, fmt.Errorf("station %s has no coordinates", destination.Code)
+	}
+	return round2(haversineKm(origin.Latitude, origin.Longitude,
+		destination.Latitude, destination.Longitude)), nil
+}
+
+// EstimatePriceFromDistance applies the per-kilometre rate for a train type
+// to a route distance. Unknown train types return 0 (no estimate).
+func EstimatePriceFromDistance(distanceKm float64, trainType string) float64 {
+	rate, ok := perKmRates[trainType]
+	if !ok {
+		return 0
+	}
+	return round2(distanceKm * rate)
+}
+
+// distanceBasedPriceEstimate is the sanity-check price for a schedule: the
+// route's great-circle distance times the train type's per-km rate. It
+// returns 0 — no estimate, skip the check — when the train or either
+// station's coordinates are missing.
+func distanceBasedPriceEstimate(trainID, originID, destinationID int) (float64, error) {
+	var trainType string
+	var oLat, oLon, dLat, dLon float64
+	err := db.QueryRow(`
+		SELECT t.type,
+		       COALESCE(o.latitude, 0), COALESCE(o.longitude, 0),
+		       COALESCE(d.latitude, 0), COALESCE(d.longitude, 0)
+		FROM trains t, stations o, stations d
+		WHERE t.id = $1 AND o.id = $2 AND d.id = $3`,
+		trainID, originID, destinationID,
+	).Scan(&trainType, &oLat, &oLon, &dLat, &dLon)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if (oLat == 0 && oLon == 0) || (dLat == 0 && dLon == 0) {
+		return 0, nil
+	}
+	return EstimatePriceFromDistance(haversineKm(oLat, oLon, dLat, dLon), trainType), nil
+}
+
+// haversineKm computes the great-circle distance between two coordinates.
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(lat2 - lat1)
+	dLon := rad(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// GroupSummary is one group booking on a departure.
+type GroupSummary struct {
+	BookingRef     string   `json:"booking_ref"`
+	ContactEmail   string   `json:"contact_email"`
+	PassengerCount int      `json:"passenger_count"`
+	TotalPrice     float64  `json:"total_price"`
+	Passengers     []string `json:"passengers"`
+}
+
+// GroupReport lists the group bookings on one departure, for event
+// coordinators travelling with large parties.
+type GroupReport struct {
+	ScheduleID  int            `json:"schedule_id"`
+	Date        string         `json:"date"`
+	TrainNumber string         `json:"train_number"`
+	Route       string         `json:"route"`
+	Departure   string         `json:"departure"`
+	Arrival     string         `json:"arrival"`
+	Groups      []GroupSummary `json:"groups"`
+}
+
+// GenerateGroupBookingReport summarises the confirmed bookings at or above
+// the group discount threshold on a schedule and date. Departures without
+// group bookings get a report with an empty groups list, not an error.
+func GenerateGroupBookingReport(scheduleID int, date string) (*GroupReport, error) {
+	report := &GroupReport{ScheduleID: scheduleID, Date: date, Groups: []GroupSummary{}}
+	err := db.QueryRow(`
+		SELECT t.number, o.name || ' -> ' || d.name,
+		       to_char(s.departure_time, 'HH24:MI'), to_char(s.arrival_time, 'HH24:MI')
+		FROM schedules s
+		JOIN trains t ON t.id = s.train_id
+		JOIN stations o ON o.id = s.origin_id
+		JOIN stations d ON d.id = s.destination_id
+		WHERE s.id = $1`, scheduleID,
+	).Scan(&report.TrainNumber, &report.Route, &report.Departure, &report.Arrival)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("schedule %d not found", scheduleID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT b.booking_ref, COALESCE(b.contact_email, ''), b.passenger_count, b.total_price,
+		       array_agg(p.name ORDER BY p.id)
+		FROM bookings b
+		JOIN passengers p ON p.booking_id = b.id
+		WHERE b.schedule_id = $1 AND b.booking_date = $2 AND b.status = 'confirmed'
+		  AND b.passenger_count >= $3
+		GROUP BY b.id
+		ORDER BY b.passenger_count DESC, b.booking_ref`,
+		scheduleID, date, cfg.GroupDiscountThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("loading group bookings for schedule %d: %w", scheduleID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g GroupSummary
+		if err := rows.Scan(&g.BookingRef, &g.ContactEmail, &g.PassengerCount,
+			&g.TotalPrice, pq.Array(&g.Passengers)); err != nil {
+			return nil, err
+		}
+		report.Groups = append(report.Groups, g)
+	}
+	return report, rows.Err()
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"fmt"
+	"time"
+)
+
+// HeatmapCell is one (date, schedule) cell of the occupancy calendar.
+type HeatmapCell struct {
+	Date         string  `json:"date"`
+	ScheduleID   int     `json:"schedule_id"`
+	TrainNumber  string  `json:"train_number"`
+	Route        string  `json:"route"`
+	OccupancyPct float64 `json:"occupancy_pct"`
+}
+
+// GetScheduleOccupancyHeatmap computes, for every operating day of every
+// active schedule in a month, the percentage of seats taken by confirmed
+// bookings. Days without bookings still produce a 0% cell so the calendar
+// grid has no holes.
+func GetScheduleOccupancyHeatmap(month, year int) ([]HeatmapCell, error) {
+	if month < 1 || month > 12 {
+		return nil, fmt.Errorf("invalid month %d", month)
+	}
+
+	type scheduleInfo struct {
+		id          int
+		trainNumber string
+		route       string
+		dayOfWeek   int
+		totalSeats  int
+	}
+
+	rows, err := db.Query(
+		`SELECT s.id, t.number, o.name || ' - ' || d.name, s.day_of_week, s.total_seats
+		 FROM schedules s
+		 JOIN trains t ON t.id = s.train_id
+		 JOIN stations o ON o.id = s.origin_id
+		 JOIN stations d ON d.id = s.destination_id
+		 WHERE s.is_active
+		 ORDER BY s.id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []scheduleInfo
+	for rows.Next() {
+		var s scheduleInfo
+		if err := rows.Scan(&s.id, &s.trainNumber, &s.route, &s.dayOfWeek, &s.totalSeats); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	// Passenger counts per (schedule, date) in one aggregate query.
+	booked := map[string]int{}
+	bookedRows, err := db.Query(
+		`SELECT b.schedule_id, to_char(b.booking_date, 'YYYY-MM-DD'), COUNT(p.id)
+		 FROM bookings b
+		 JOIN passengers p ON p.booking_id = b.id
+		 WHERE b.status = 'confirmed' AND b.booking_date >= $1 AND b.booking_date < $2
+		 GROUP BY b.schedule_id, b.booking_date`,
+		monthStart, monthEnd,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer bookedRows.Close()
+	for bookedRows.Next() {
+		var scheduleID, count int
+		var date string
+		if err := bookedRows.Scan(&scheduleID, &date, &count); err != nil {
+			return nil, err
+		}
+		booked[fmt.Sprintf("%d/%s", scheduleID, date)] = count
+	}
+	if err := bookedRows.Err(); err != nil {
+		return nil, err
+	}
+
+	var cells []HeatmapCell
+	for day := monthStart; day.Before(monthEnd); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		for _, s := range schedules {
+			if int(day.Weekday()) != s.dayOfWeek {
+				continue
+			}
+			pct := 0.0
+			if s.totalSeats > 0 {
+				pct = float64(booked[fmt.Sprintf("%d/%s", s.id, date)]) / float64(s.totalSeats) * 100
+			}
+			cells = append(cells, HeatmapCell{
+				Date:         date,
+				ScheduleID:   s.id,
+				TrainNumber:  s.trainNumber,
+				Route:        s.route,
+				OccupancyPct: round2(pct),
+			})
+		}
+	}
+	return cells, nil
+}
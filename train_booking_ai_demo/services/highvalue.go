@@ -0,0 +1,82 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"trainbooking/models"
+)
+
+// HighValueSummary aggregates the bookings matching a high-value query,
+// across all pages.
+type HighValueSummary struct {
+	Count        int     `json:"count"`
+	TotalRevenue float64 `json:"total_revenue"`
+	AvgPrice     float64 `json:"avg_price"`
+}
+
+// GetHighValueBookings returns the bookings with total_price at or above
+// threshold whose travel date falls in [from, to), most expensive first,
+// with contact email included for VIP follow-up. Cancelled bookings are
+// excluded unless includeCancelled is set. The summary covers the whole
+// result set, not just the requested page.
+func GetHighValueBookings(threshold float64, from, to time.Time, includeCancelled bool, page, pageSize int) ([]models.Booking, HighValueSummary, error) {
+	if threshold < 0 {
+		return nil, HighValueSummary{}, fmt.Errorf("threshold must not be negative")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	statusFilter := " AND b.status <> 'cancelled'"
+	if includeCancelled {
+		statusFilter = ""
+	}
+
+	var summary HighValueSummary
+	err := db.QueryRow(
+		`SELECT COUNT(*), COALESCE(SUM(b.total_price), 0), COALESCE(AVG(b.total_price), 0)
+		 FROM bookings b
+		 WHERE b.total_price >= $1 AND b.booking_date >= $2 AND b.booking_date < $3`+statusFilter,
+		threshold, from, to,
+	).Scan(&summary.Count, &summary.TotalRevenue, &summary.AvgPrice)
+	if err != nil {
+		return nil, summary, fmt.Errorf("summarising high-value bookings: %w", err)
+	}
+	summary.TotalRevenue = round2(summary.TotalRevenue)
+	summary.AvgPrice = round2(summary.AvgPrice)
+
+	rows, err := db.Query(
+		`SELECT b.id, b.booking_ref, b.schedule_id, to_char(b.booking_date, 'YYYY-MM-DD'),
+		        b.status, b.total_price, b.passenger_count, COALESCE(b.contact_email, ''),
+		        t.number, o.name, d.name
+		 FROM bookings b
+		 JOIN schedules s ON s.id = b.schedule_id
+		 JOIN trains t ON t.id = s.train_id
+		 JOIN stations o ON o.id = s.origin_id
+		 JOIN stations d ON d.id = s.destination_id
+		 WHERE b.total_price >= $1 AND b.booking_date >= $2 AND b.booking_date < $3`+statusFilter+`
+		 ORDER BY b.total_price DESC, b.id
+		 LIMIT $4 OFFSET $5`,
+		threshold, from, to, pageSize, (page-1)*pageSize,
+	)
+	if err != nil {
+		return nil, summary, fmt.Errorf("loading high-value bookings: %w", err)
+	}
+	defer rows.Close()
+
+	bookings := []models.Booking{}
+	for rows.Next() {
+		var b models.Booking
+		if err := rows.Scan(&b.ID, &b.BookingRef, &b.ScheduleID, &b.BookingDate,
+			&b.Status, &b.TotalPrice, &b.PassengerCount, &b.ContactEmail,
+			&b.TrainNumber, &b.Origin, &b.Destination); err != nil {
+			return nil, summary, err
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, summary, rows.Err()
+}
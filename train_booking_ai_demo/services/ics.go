@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExportBookingToICS renders a booking as an RFC 5545 iCalendar file with one
+// VEVENT covering the journey. Times are floating local times, matching how
+// the rest of the demo treats timetables. The format is simple enough that we
+// emit it directly rather than pulling in a calendar dependency.
+func ExportBookingToICS(bookingRef string) ([]byte, error) {
+	booking, err := GetBooking(bookingRef)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := time.Parse("2006-01-02 15:04", booking.BookingDate+" "+booking.DepartureTime)
+	if err != nil {
+		return nil, fmt.Errorf("parsing departure for %s: %w", bookingRef, err)
+	}
+	end, err := time.Parse("2006-01-02 15:04", booking.BookingDate+" "+booking.ArrivalTime)
+	if err != nil {
+		return nil, fmt.Errorf("parsing arrival for %s: %w", bookingRef, err)
+	}
+	if !end.After(start) {
+		// Overnight service: arrival is on the following day.
+		end = end.Add(24 * time.Hour)
+	}
+
+	description := make([]string, 0, len(booking.Passengers)+1)
+	description = append(description, fmt.Sprintf("Booking %s", booking.BookingRef))
+	for _, p := range booking.Passengers {
+		line := p.Name
+		if p.SeatNumber != "" {
+			line += fmt.Sprintf(" (seat %s)", p.SeatNumber)
+		}
+		description = append(description, line)
+	}
+
+	var b strings.Builder
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//trainbooking//booking calendar//EN")
+	writeICSLine(&b, "BEGIN:VEVENT")
+	writeICSLine(&b, "UID:"+booking.BookingRef+"@trainbooking")
+	writeICSLine(&b, "DTSTAMP:"+time.Now().UTC().Format("20060102T150405Z"))
+	writeICSLine(&b, "DTSTART:"+start.Format("20060102T150405"))
+	writeICSLine(&b, "DTEND:"+end.Format("20060102T150405"))
+	writeICSLine(&b, "SUMMARY:"+escapeICSText(fmt.Sprintf("Train %s: %s → %s",
+		booking.TrainNumber, booking.Origin, booking.Destination)))
+	writeICSLine(&b, "DESCRIPTION:"+escapeICSText(strings.Join(description, "\n")))
+	writeICSLine(&b, "LOCATION:"+escapeICSText(booking.Origin))
+	writeICSLine(&b, "END:VEVENT")
+	writeICSLine(&b, "END:VCALENDAR")
+	return []byte(b.String()), nil
+}
+
+// writeICSLine appends one content line with CRLF termination, folding at 75
+// octets as RFC 5545 section 3.1 requires.
+func writeICSLine(b *strings.Builder, line string) {
+	for len(line) > 75 {
+		cut := 75
+		// Never split a multi-byte UTF-8 sequence.
+		for cut > 1 && line[cut]&0xc0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line + "\r\n")
+}
+
+// escapeICSText escapes the characters RFC 5545 treats specially in text
+// values: backslash, semicolon, comma and newline.
+func escapeICSText(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, ";", `\;`)
+	s = strings.ReplaceAll(s, ",", `\,`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return s
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LoadFactorReport summarises how full a schedule ran over its recent
+// operating days.
+type LoadFactorReport struct {
+	ScheduleID     int     `json:"schedule_id"`
+	AvgLoadFactor  float64 `json:"avg_load_factor"`
+	PeakLoadFactor float64 `json:"peak_load_factor"`
+	PeakDate       string  `json:"peak_date"`
+	MinLoadFactor  float64 `json:"min_load_factor"`
+	MinDate        string  `json:"min_date"`
+	OperatingDays  int     `json:"operating_days"`
+}
+
+// GetScheduleLoadFactor returns the average load factor (booked seats over
+// total seats, 0..1) across the schedule's operating days in the last
+// weeksBack weeks.
+func GetScheduleLoadFactor(scheduleID int, weeksBack int) (float64, error) {
+	report, err := GetScheduleLoadFactorReport(scheduleID, weeksBack)
+	if err != nil {
+		return 0, err
+	}
+	return report.AvgLoadFactor, nil
+}
+
+// GetScheduleLoadFactorReport computes the full load-factor summary behind
+// GetScheduleLoadFactor: average, peak and minimum with their dates. Days
+// without bookings count as 0% — an empty train still ran.
+func GetScheduleLoadFactorReport(scheduleID int, weeksBack int) (*LoadFactorReport, error) {
+	if weeksBack < 1 || weeksBack > 52 {
+		weeksBack = 4
+	}
+
+	var dayOfWeek, totalSeats int
+	err := db.QueryRow(
+		`SELECT day_of_week, total_seats FROM schedules WHERE id = $1`, scheduleID,
+	).Scan(&dayOfWeek, &totalSeats)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("schedule %d not found", scheduleID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if totalSeats == 0 {
+		return nil, fmt.Errorf("schedule %d has no seats", scheduleID)
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -(weeksBack*7 - 1))
+
+	booked := map[string]int{}
+	rows, err := db.Query(
+		`SELECT to_char(b.booking_date, 'YYYY-MM-DD'), COUNT(p.id)
+		 FROM bookings b
+		 JOIN passengers p ON p.booking_id = b.id
+		 WHERE b.schedule_id = $1 AND b.status = 'confirmed'
+		   AND b.booking_date >= $2 AND b.booking_date <= $3
+		 GROUP BY b.booking_date`,
+		scheduleID, from, today,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var date string
+		var count int
+		if err := rows.Scan(&date, &count); err != nil {
+			return nil, err
+		}
+		booked[date] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &LoadFactorReport{ScheduleID: scheduleID, MinLoadFactor: 1}
+	sum := 0.0
+	for day := from; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if int(day.Weekday()) != dayOfWeek {
+			continue
+		}
+		date := day.Format("2006-01-02")
+		load := float64(booked[date]) / float64(totalSeats)
+		sum += load
+		report.OperatingDays++
+		if load >= report.PeakLoadFactor {
+			report.PeakLoadFactor = load
+			report.PeakDate = date
+		}
+		if load <= report.MinLoadFactor {
+			report.MinLoadFactor = load
+			report.MinDate = date
+		}
+	}
+	if report.OperatingDays == 0 {
+		report.MinLoadFactor = 0
+		return report, nil
+	}
+	report.AvgLoadFactor = round2(sum / float64(report.OperatingDays))
+	report.PeakLoadFactor = round2(report.PeakLoadFactor)
+	report.MinLoadFactor = round2(report.MinLoadFactor)
+	return report, nil
+}
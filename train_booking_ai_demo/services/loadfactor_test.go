@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestGetScheduleLoadFactor seeds a dedicated schedule with four weeks of
+// bookings at known occupancies (10%, 20%, 30%, 40% of 100 seats) and checks
+// the computed average.
+func TestGetScheduleLoadFactor(t *testing.T) {
+	testDB := setupTestDB(t)
+
+	var trainID, originID, destinationID int
+	if err := testDB.QueryRow(`SELECT id FROM trains ORDER BY id LIMIT 1`).Scan(&trainID); err != nil {
+		t.Fatalf("loading seed train: %v", err)
+	}
+	if err := testDB.QueryRow(`SELECT id FROM stations WHERE code = 'MI'`).Scan(&originID); err != nil {
+		t.Fatalf("loading seed station: %v", err)
+	}
+	if err := testDB.QueryRow(`SELECT id FROM stations WHERE code = 'RM'`).Scan(&destinationID); err != nil {
+		t.Fatalf("loading seed station: %v", err)
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	var scheduleID int
+	err := testDB.QueryRow(
+		`INSERT INTO schedules (train_id, origin_id, destination_id, departure_time, arrival_time,
+		                        day_of_week, price_base, total_seats, available_seats, is_active)
+		 VALUES ($1, $2, $3, '23:40', '23:50', $4, 10, 100, 100, TRUE)
+		 RETURNING id`,
+		trainID, originID, destinationID, int(today.Weekday()),
+	).Scan(&scheduleID)
+	if err != nil {
+		t.Fatalf("seeding schedule: %v", err)
+	}
+	t.Cleanup(func() {
+		testDB.Exec(`DELETE FROM passengers WHERE booking_id IN (SELECT id FROM bookings WHERE schedule_id = $1)`, scheduleID)
+		testDB.Exec(`DELETE FROM bookings WHERE schedule_id = $1`, scheduleID)
+		testDB.Exec(`DELETE FROM schedules WHERE id = $1`, scheduleID)
+	})
+
+	// One operating day per week for 4 weeks: today, -7, -14, -21 days,
+	// with 40, 30, 20, 10 passengers respectively.
+	for week, passengers := range []int{40, 30, 20, 10} {
+		date := today.AddDate(0, 0, -7*week)
+		var bookingID int
+		err := testDB.QueryRow(
+			`INSERT INTO bookings (booking_ref, schedule_id, booking_date, status, total_price, passenger_count)
+			 VALUES ($1, $2, $3, 'confirmed', $4, $5)
+			 RETURNING id`,
+			fmt.Sprintf("TRN-0000-9%04d", week), scheduleID, date, float64(passengers)*10, passengers,
+		).Scan(&bookingID)
+		if err != nil {
+			t.Fatalf("seeding booking: %v", err)
+		}
+		_, err = testDB.Exec(
+			`INSERT INTO passengers (booking_id, name, passenger_type, price)
+			 SELECT $1, 'Passenger ' || n, 'adult', 10 FROM generate_series(1, $2) n`,
+			bookingID, passengers,
+		)
+		if err != nil {
+			t.Fatalf("seeding passengers: %v", err)
+		}
+	}
+
+	report, err := GetScheduleLoadFactorReport(scheduleID, 4)
+	if err != nil {
+		t.Fatalf("GetScheduleLoadFactorReport: %v", err)
+	}
+	if report.OperatingDays != 4 {
+		t.Fatalf("operating days = %d, want 4", report.OperatingDays)
+	}
+	if report.AvgLoadFactor != 0.25 {
+		t.Errorf("avg load factor = %v, want 0.25", report.AvgLoadFactor)
+	}
+	if report.PeakLoadFactor != 0.40 || report.PeakDate != today.Format("2006-01-02") {
+		t.Errorf("peak = %v on %s, want 0.40 today", report.PeakLoadFactor, report.PeakDate)
+	}
+	if report.MinLoadFactor != 0.10 {
+		t.Errorf("min load factor = %v, want 0.10", report.MinLoadFactor)
+	}
+
+	avg, err := GetScheduleLoadFactor(scheduleID, 4)
+	if err != nil {
+		t.Fatalf("GetScheduleLoadFactor: %v", err)
+	}
+	if avg != report.AvgLoadFactor {
+		t.Errorf("GetScheduleLoadFactor = %v, want %v", avg, report.AvgLoadFactor)
+	}
+}
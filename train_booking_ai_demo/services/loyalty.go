@@ -0,0 +1,131 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+
+	"trainbooking/models"
+)
+
+// LoyaltyCard is a row of the loyalty_cards table.
+type LoyaltyCard struct {
+	CardNumber    string `json:"card_number"`
+	PassengerName string `json:"passenger_name"`
+	PointsBalance int    `json:"points_balance"`
+	Tier          string `json:"tier"`
+}
+
+// GetLoyaltyCard looks up a card by number.
+func GetLoyaltyCard(cardNumber string) (*LoyaltyCard, error) {
+	var card LoyaltyCard
+	err := db.QueryRow(
+		`SELECT card_number, passenger_name, points_balance, tier
+		 FROM loyalty_cards WHERE card_number = $1`,
+		cardNumber,
+	).Scan(&card.CardNumber, &card.PassengerName, &card.PointsBalance, &card.Tier)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("loyalty card %s not found", cardNumber)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// validateLoyaltyCards checks that every card number given in a booking
+// request exists, before the booking transaction starts.
+func validateLoyaltyCards(passengers []models.PassengerCreateRequest) error {
+	for i, p := range passengers {
+		if p.LoyaltyCardNumber == "" {
+			continue
+		}
+		if _, err := GetLoyaltyCard(p.LoyaltyCardNumber); err != nil {
+			return fmt.Errorf("passenger %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// LoyaltyBooking is a booking linked to a loyalty card, with the points the
+// card earned on it.
+type LoyaltyBooking struct {
+	models.Booking
+	PointsEarned int `json:"points_earned"`
+}
+
+// GetBookingsByLoyaltyCard lists the bookings a loyalty card earned points
+// on, newest travel date first, paginated.
+func GetBookingsByLoyaltyCard(cardNumber string, page, pageSize int) ([]LoyaltyBooking, error) {
+	if _, err := GetLoyaltyCard(cardNumber); err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 10
+	}
+
+	rows, err := db.Query(
+		`SELECT b.booking_ref, SUM(FLOOR(p.price * 10))::int
+		 FROM bookings b
+		 JOIN passengers p ON p.booking_id = b.id
+		 WHERE p.loyalty_card_number = $1
+		 GROUP BY b.id
+		 ORDER BY b.booking_date DESC
+		 LIMIT $2 OFFSET $3`,
+		cardNumber, pageSize, (page-1)*pageSize,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("loading loyalty bookings: %w", err)
+	}
+	defer rows.Close()
+
+	type refPoints struct {
+		ref    string
+		points int
+	}
+	var refs []refPoints
+	for rows.Next() {
+		var rp refPoints
+		if err := rows.Scan(&rp.ref, &rp.points); err != nil {
+			return nil, err
+		}
+		refs = append(refs, rp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	bookings := make([]LoyaltyBooking, 0, len(refs))
+	for _, rp := range refs {
+		booking, err := GetBooking(rp.ref)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, LoyaltyBooking{Booking: *booking, PointsEarned: rp.points})
+	}
+	return bookings, nil
+}
+
+// awardLoyaltyPoints credits FLOOR(price * 10) points to each passenger's
+// card after a booking commits. Point crediting is best-effort: a failure
+// here must not undo a confirmed booking, so it is only logged.
+func awardLoyaltyPoints(bookingRef string, passengers []models.Passenger) {
+	for _, p := range passengers {
+		if p.LoyaltyCardNumber == "" {
+			continue
+		}
+		points := int(math.Floor(p.Price * 10))
+		_, err := db.Exec(
+			`UPDATE loyalty_cards SET points_balance = points_balance + $1 WHERE card_number = $2`,
+			points, p.LoyaltyCardNumber,
+		)
+		if err != nil {
+			log.Printf("crediting %d points to %s for booking %s: %v",
+				points, p.LoyaltyCardNumber, bookingRef, err)
+		}
+	}
+}
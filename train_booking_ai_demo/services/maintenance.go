@@ -0,0 +1,16 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ReindexStationSimilarity rebuilds the trigram index behind fuzzy station
+// matching. CONCURRENTLY keeps searches running while it rebuilds, so this
+// is safe to call on a live system.
+func ReindexStationSimilarity(db *sql.DB) error {
+	if _, err := db.Exec(`REINDEX INDEX CONCURRENTLY idx_stations_name_trgm`); err != nil {
+		return fmt.Errorf("reindexing station trigram index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+
+	"trainbooking/models"
+)
+
+// GetBookingsByScheduleAndDate returns all confirmed bookings, with their
+// passengers, for one schedule on one travel date, ordered by booking_ref.
+// Used by the conductor manifest endpoint.
+func GetBookingsByScheduleAndDate(scheduleID int, date string) ([]models.Booking, error) {
+	rows, err := db.Query(
+		`SELECT b.id, b.booking_ref, b.schedule_id, to_char(b.booking_date, 'YYYY-MM-DD'),
+		        b.status, b.total_price, b.passenger_count, b.created_at
+		 FROM bookings b
+		 WHERE b.schedule_id = $1 AND b.booking_date = $2 AND b.status = 'confirmed'
+		 ORDER BY b.booking_ref`,
+		scheduleID, date,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying manifest bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []models.Booking
+	for rows.Next() {
+		var b models.Booking
+		if err := rows.Scan(
+			&b.ID, &b.BookingRef, &b.ScheduleID, &b.BookingDate,
+			&b.Status, &b.TotalPrice, &b.PassengerCount, &b.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range bookings {
+		passengers, err := getPassengers(bookings[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		bookings[i].Passengers = passengers
+	}
+	return bookings, nil
+}
+
+// GetScheduleSummary returns the train number and "Origin - Destination"
+// route label of a schedule.
+func GetScheduleSummary(scheduleID int) (trainNumber, route string, err error) {
+	err = db.QueryRow(
+		`SELECT t.number, o.name || ' - ' || d.name
+		 FROM schedules s
+		 JOIN trains t ON t.id = s.train_id
+		 JOIN stations o ON o.id = s.origin_id
+		 JOIN stations d ON d.id = s.destination_id
+		 WHERE s.id = $1`,
+		scheduleID,
+	).Scan(&trainNumber, &route)
+	if err != nil {
+		return "", "", fmt.Errorf("loading schedule %d: %w", scheduleID, err)
+	}
+	return trainNumber, route, nil
+}
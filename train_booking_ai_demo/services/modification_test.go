@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestBookingModificationGate seeds a confirmed booking and checks the
+// modification limit at its exact boundary: one prior modification still
+// passes with the default limit of 2, the second prior modification blocks.
+func TestBookingModificationGate(t *testing.T) {
+	testDB := setupTestDB(t)
+	cfg.MaxBookingModifications = 2
+
+	var scheduleID, dayOfWeek int
+	err := testDB.QueryRow(
+		`SELECT id, day_of_week FROM schedules WHERE is_active ORDER BY id LIMIT 1`,
+	).Scan(&scheduleID, &dayOfWeek)
+	if err != nil {
+		t.Fatalf("loading seed schedule: %v", err)
+	}
+
+	// The next date this schedule operates, so ChangeBookingDate only fails
+	// on the modification gate.
+	date := time.Now().AddDate(0, 0, 1)
+	for int(date.Weekday()) != dayOfWeek {
+		date = date.AddDate(0, 0, 1)
+	}
+	newDate := date.AddDate(0, 0, 7).Format("2006-01-02")
+
+	bookingRef := fmt.Sprintf("TRN-0000-8%04d", time.Now().UnixNano()%10000)
+	_, err = testDB.Exec(
+		`INSERT INTO bookings (booking_ref, schedule_id, booking_date, status, total_price, passenger_count)
+		 VALUES ($1, $2, $3, 'confirmed', 50, 1)`,
+		bookingRef, scheduleID, date.Format("2006-01-02"),
+	)
+	if err != nil {
+		t.Fatalf("seeding booking: %v", err)
+	}
+	t.Cleanup(func() {
+		testDB.Exec(`DELETE FROM booking_events WHERE booking_ref = $1`, bookingRef)
+		testDB.Exec(`DELETE FROM bookings WHERE booking_ref = $1`, bookingRef)
+	})
+
+	addEvent := func(eventType string) {
+		t.Helper()
+		_, err := testDB.Exec(
+			`INSERT INTO booking_events (booking_ref, event_type, details) VALUES ($1, $2, '{}')`,
+			bookingRef, eventType,
+		)
+		if err != nil {
+			t.Fatalf("seeding booking event: %v", err)
+		}
+	}
+
+	// Cancellation events are not modifications and must not count.
+	addEvent("cancelled")
+	if n, err := GetBookingModificationCount(bookingRef); err != nil || n != 0 {
+		t.Fatalf("modification count = %d, %v; want 0, nil", n, err)
+	}
+
+	// One modification: still below the limit of 2, the change succeeds.
+	addEvent("passenger_updated")
+	if n, _ := GetBookingModificationCount(bookingRef); n != 1 {
+		t.Fatalf("modification count = %d, want 1", n)
+	}
+	if _, err := ChangeBookingDate(bookingRef, newDate); err != nil {
+		t.Fatalf("ChangeBookingDate below limit: %v", err)
+	}
+
+	// The date change above recorded a second modification event, so the
+	// booking is now at the limit and further changes are refused.
+	if n, _ := GetBookingModificationCount(bookingRef); n != 2 {
+		t.Fatalf("modification count = %d, want 2", n)
+	}
+	_, err = ChangeBookingDate(bookingRef, date.Format("2006-01-02"))
+	if !errors.Is(err, ErrModificationLimit) {
+		t.Fatalf("ChangeBookingDate at limit = %v, want ErrModificationLimit", err)
+	}
+}
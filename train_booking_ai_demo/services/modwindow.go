@@ -0,0 +1,52 @@
+package services
+
+import "time"
+
+// ModificationWindow tells a user until when their booking can still be
+// cancelled or changed, and what a cancellation right now would refund.
+type ModificationWindow struct {
+	CanCancel               bool      `json:"can_cancel"`
+	CancelDeadline          time.Time `json:"cancel_deadline"`
+	CancelRefundPct         float64   `json:"cancel_refund_pct"`
+	CanModifyDate           bool      `json:"can_modify_date"`
+	ModifyDateDeadline      time.Time `json:"modify_date_deadline"`
+	CanModifyPassenger      bool      `json:"can_modify_passenger"`
+	ModifyPassengerDeadline time.Time `json:"modify_passenger_deadline"`
+}
+
+// GetBookingModificationWindow computes the deadlines for a booking from its
+// departure timestamp and the configured cutoffs. Cancellation stays open
+// until departure (the refund just shrinks); date changes close
+// cfg.ModifyDateCutoffHours before departure and are also subject to the
+// modification-count cap, passenger-name changes close
+// cfg.ModifyPassengerCutoffHours before.
+func GetBookingModificationWindow(bookingRef string) (ModificationWindow, error) {
+	booking, err := GetBooking(bookingRef)
+	if err != nil {
+		return ModificationWindow{}, err
+	}
+	departure := departureTimestamp(booking)
+	confirmed := booking.Status == "confirmed"
+	now := time.Now()
+
+	w := ModificationWindow{
+		CancelDeadline:          departure,
+		CancelRefundPct:         refundPercentage(departure) * 100,
+		ModifyDateDeadline:      departure.Add(-time.Duration(cfg.ModifyDateCutoffHours) * time.Hour),
+		ModifyPassengerDeadline: departure.Add(-time.Duration(cfg.ModifyPassengerCutoffHours) * time.Hour),
+	}
+	w.CanCancel = confirmed && now.Before(w.CancelDeadline)
+	w.CanModifyDate = confirmed && now.Before(w.ModifyDateDeadline)
+	w.CanModifyPassenger = confirmed && now.Before(w.ModifyPassengerDeadline)
+
+	if w.CanModifyDate {
+		mods, err := GetBookingModificationCount(bookingRef)
+		if err != nil {
+			return ModificationWindow{}, err
+		}
+		if mods >= cfg.MaxBookingModifications {
+			w.CanModifyDate = false
+		}
+	}
+	return w, nil
+}
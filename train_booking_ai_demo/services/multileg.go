@@ -0,0 +1,118 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"trainbooking/models"
+)
+
+// minConnectionTime is the minimum transfer time between two legs.
+const minConnectionTime = 20 * time.Minute
+
+// MultiLegJourney is a journey composed of one or more legs with a
+// connection at an intermediate station.
+type MultiLegJourney struct {
+	Legs            []models.SearchResponse `json:"legs"`
+	TotalPrice      float64                 `json:"total_price"`
+	TotalDuration   string                  `json:"total_duration"`
+	ConnectionCount int                     `json:"connection_count"`
+}
+
+// SearchMultiLeg finds journeys between two stations: direct trains as
+// single-leg journeys, plus one-connection journeys via every feasible
+// intermediate station. The "max_connections" filter limits the number of
+// changes (0 for direct only) and sort_by "connections_asc" bubbles direct
+// routes to the top.
+func SearchMultiLeg(req models.SearchRequest) ([]MultiLegJourney, error) {
+	origin, err := FindStationByNameOrCode(req.Origin)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := FindStationByNameOrCode(req.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	maxConnections := -1 // unlimited
+	if m, ok := req.Filters["max_connections"].(float64); ok && m >= 0 {
+		maxConnections = int(m)
+	}
+
+	var journeys []MultiLegJourney
+	direct, err := SearchTrains(models.SearchRequest{
+		Origin: origin.Code, Destination: destination.Code,
+		Date: req.Date, PassengerCount: req.PassengerCount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range direct {
+		journeys = append(journeys, MultiLegJourney{
+			Legs:          []models.SearchResponse{d},
+			TotalPrice:    d.PricePerAdult,
+			TotalDuration: d.Duration,
+		})
+	}
+	if maxConnections == 0 {
+		return journeys, nil
+	}
+
+	stations, err := GetAllStations()
+	if err != nil {
+		return nil, err
+	}
+	for _, via := range stations {
+		if via.ID == origin.ID || via.ID == destination.ID {
+			continue
+		}
+
+		firstLegs, err := SearchTrains(models.SearchRequest{
+			Origin: origin.Code, Destination: via.Code,
+			Date: req.Date, PassengerCount: req.PassengerCount,
+		})
+		if err != nil || len(firstLegs) == 0 {
+			continue
+		}
+		secondLegs, err := SearchTrains(models.SearchRequest{
+			Origin: via.Code, Destination: destination.Code,
+			Date: req.Date, PassengerCount: req.PassengerCount,
+		})
+		if err != nil || len(secondLegs) == 0 {
+			continue
+		}
+
+		for _, first := range firstLegs {
+			for _, second := range secondLegs {
+				if !connectionFeasible(first.ArrivalTime, second.DepartureTime) {
+					continue
+				}
+				journeys = append(journeys, MultiLegJourney{
+					Legs:            []models.SearchResponse{first, second},
+					TotalPrice:      first.PricePerAdult + second.PricePerAdult,
+					TotalDuration:   formatDuration(first.DepartureTime, second.ArrivalTime),
+					ConnectionCount: 1,
+				})
+				break // keep only the earliest feasible connection per first leg
+			}
+		}
+	}
+
+	if req.SortBy == "connections_asc" {
+		sort.SliceStable(journeys, func(i, j int) bool {
+			return journeys[i].ConnectionCount < journeys[j].ConnectionCount
+		})
+	}
+	return journeys, nil
+}
+
+// connectionFeasible reports whether a transfer from an arrival to the next
+// departure (both HH:MM, same day) leaves at least minConnectionTime.
+func connectionFeasible(arrival, nextDeparture string) bool {
+	arr, err1 := time.Parse("15:04", arrival)
+	dep, err2 := time.Parse("15:04", nextDeparture)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	return dep.Sub(arr) >= minConnectionTime
+}
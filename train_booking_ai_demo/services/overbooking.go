@@ -0,0 +1,88 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// OverbookingReport compares what was sold against a schedule's capacity on
+// one travel date. Discrepancy is how many passengers are over capacity.
+type OverbookingReport struct {
+	ScheduleID          int    `json:"schedule_id"`
+	Date                string `json:"date"`
+	TotalSeats          int    `json:"total_seats"`
+	ConfirmedPassengers int    `json:"confirmed_passengers"`
+	AvailableSeats      int    `json:"available_seats"`
+	IsOverbooked        bool   `json:"is_overbooked"`
+	Discrepancy         int    `json:"discrepancy"`
+}
+
+// CheckOverbooking diagnoses one schedule and travel date: it recounts the
+// confirmed passengers from the bookings table and flags the date as
+// overbooked when they exceed the schedule's capacity. Used to detect damage
+// from booking race conditions.
+func CheckOverbooking(scheduleID int, date string) (OverbookingReport, error) {
+	report := OverbookingReport{ScheduleID: scheduleID, Date: date}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return report, fmt.Errorf("invalid date %q: use YYYY-MM-DD", date)
+	}
+
+	err := db.QueryRow(
+		`SELECT total_seats, available_seats FROM schedules WHERE id = $1`, scheduleID,
+	).Scan(&report.TotalSeats, &report.AvailableSeats)
+	if err == sql.ErrNoRows {
+		return report, fmt.Errorf("schedule %d not found", scheduleID)
+	}
+	if err != nil {
+		return report, err
+	}
+
+	err = db.QueryRow(
+		`SELECT COALESCE(SUM(passenger_count), 0) FROM bookings
+		 WHERE schedule_id = $1 AND booking_date = $2 AND status = 'confirmed'`,
+		scheduleID, date,
+	).Scan(&report.ConfirmedPassengers)
+	if err != nil {
+		return report, err
+	}
+
+	if report.ConfirmedPassengers > report.TotalSeats {
+		report.IsOverbooked = true
+		report.Discrepancy = report.ConfirmedPassengers - report.TotalSeats
+	}
+	return report, nil
+}
+
+// FindOverbookedSchedules sweeps every upcoming travel date with confirmed
+// bookings and returns the ones sold over capacity. Run nightly so race
+// damage is caught before departure day.
+func FindOverbookedSchedules() ([]OverbookingReport, error) {
+	rows, err := db.Query(
+		`SELECT b.schedule_id, to_char(b.booking_date, 'YYYY-MM-DD'),
+		        s.total_seats, s.available_seats, SUM(b.passenger_count)
+		 FROM bookings b
+		 JOIN schedules s ON s.id = b.schedule_id
+		 WHERE b.status = 'confirmed' AND b.booking_date >= CURRENT_DATE
+		 GROUP BY b.schedule_id, b.booking_date, s.total_seats, s.available_seats
+		 HAVING SUM(b.passenger_count) > s.total_seats`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("sweeping for overbooking: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []OverbookingReport
+	for rows.Next() {
+		var r OverbookingReport
+		if err := rows.Scan(
+			&r.ScheduleID, &r.Date, &r.TotalSeats, &r.AvailableSeats, &r.ConfirmedPassengers,
+		); err != nil {
+			return nil, err
+		}
+		r.IsOverbooked = true
+		r.Discrepancy = r.ConfirmedPassengers - r.TotalSeats
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+
+	"trainbooking/models"
+)
+
+// nameTitleCaser title-cases passenger names with Unicode-aware rules, so
+// accented initials like "ëmília" come out right where strings.Title would
+// not.
+var nameTitleCaser = cases.Title(language.Italian)
+
+// honorifics that imply an adult passenger when no date of birth is known.
+var adultHonorifics = []string{"sig.", "sig.ra", "sig.na", "dr.", "dott.", "prof."}
+
+// SuggestPassengerType infers a passenger type when the AI receives
+// incomplete passenger info. With a date of birth the age decides, per the
+// passengerTypes brackets; with only a name, Italian honorifics mark an
+// adult and "adult" is the fallback either way.
+func SuggestPassengerType(name string, dateOfBirth *time.Time) string {
+	if dateOfBirth != nil {
+		return passengerTypeForAge(ageAt(*dateOfBirth, time.Now()))
+	}
+
+	lower := strings.ToLower(strings.TrimSpace(name))
+	for _, h := range adultHonorifics {
+		if strings.HasPrefix(lower, h) {
+			return "adult"
+		}
+	}
+	return "adult"
+}
+
+// NormalisePassengerName cleans up a name as the AI's JSON output delivers
+// it: whitespace trimmed, internal runs collapsed to single spaces, and each
+// word title-cased. "MARCO  ROSSI" and "marco rossi" both store as
+// "Marco Rossi".
+func NormalisePassengerName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		words[i] = nameTitleCaser.String(w)
+	}
+	return strings.Join(words, " ")
+}
+
+// normalisePassengerNames applies NormalisePassengerName to a request's
+// passengers in place, before validation and storage.
+func normalisePassengerNames(passengers []models.PassengerCreateRequest) {
+	for i := range passengers {
+		passengers[i].Name = NormalisePassengerName(passengers[i].Name)
+	}
+}
+
+// ValidatePassengerName checks a passenger name against the rules tickets
+// are printed with: at least 2 and at most 100 characters after trimming,
+// letters and spaces only, plus "-" and "'" for compound names.
+func ValidatePassengerName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("name is empty")
+	}
+	if len([]rune(name)) < 2 {
+		return fmt.Errorf("name %q is too short (minimum 2 characters)", name)
+	}
+	if len([]rune(name)) > 100 {
+		return fmt.Errorf("name is too long (maximum 100 characters)")
+	}
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsSpace(r) || r == '-' || r == '\'' {
+			continue
+		}
+		return fmt.Errorf("name %q contains invalid character %q", name, r)
+	}
+	return nil
+}
+
+// validatePassengerNames runs ValidatePassengerName over a request's
+// passengers, naming the offending entry by its (1-based) position.
+func validatePassengerNames(passengers []models.PassengerCreateRequest) error {
+	for i, p := range passengers {
+		if err := ValidatePassengerName(p.Name); err != nil {
+			return fmt.Errorf("passenger %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ageAt returns full years between birth and a reference date.
+func ageAt(birth, at time.Time) int {
+	years := at.Year() - birth.Year()
+	if at.YearDay() < birth.YearDay() {
+		years--
+	}
+	return years
+}
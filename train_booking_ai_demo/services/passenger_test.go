@@ -0,0 +1,38 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePassengerName(t *testing.T) {
+	valid := []string{"Mario Rossi", "Anna-Maria De Luca", "D'Angelo", "  Giulia Bianchi  ", "José"}
+	for _, name := range valid {
+		if err := ValidatePassengerName(name); err != nil {
+			t.Errorf("ValidatePassengerName(%q) = %v, want nil", name, err)
+		}
+	}
+
+	invalid := []string{"", "   ", "A", "Mario123", "Rossi;DROP TABLE", "Mario_Rossi", strings.Repeat("a", 101)}
+	for _, name := range invalid {
+		if err := ValidatePassengerName(name); err == nil {
+			t.Errorf("ValidatePassengerName(%q) = nil, want error", name)
+		}
+	}
+}
+
+func TestNormalisePassengerName(t *testing.T) {
+	cases := map[string]string{
+		"MARCO ROSSI":      "Marco Rossi",
+		"marco rossi":      "Marco Rossi",
+		"Marco  Rossi":     "Marco Rossi",
+		"  anna bianchi  ": "Anna Bianchi",
+		"ëmília nagy":      "Ëmília Nagy",
+		"josé GARCÍA":      "José García",
+	}
+	for input, want := range cases {
+		if got := NormalisePassengerName(input); got != want {
+			t.Errorf("NormalisePassengerName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
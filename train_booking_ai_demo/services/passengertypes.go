@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PassengerTypeDefinition is the single source of truth for one passenger
+// type: the code stored with bookings, its label and age bracket, and the
+// discount off the adult fare.
+type PassengerTypeDefinition struct {
+	Code        string `json:"code"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	// MinAge and MaxAge bound the bracket; nil means unbounded. Adult has
+	// no bracket and is the fallback for everyone else.
+	MinAge          *int    `json:"min_age,omitempty"`
+	MaxAge          *int    `json:"max_age,omitempty"`
+	DiscountPercent float64 `json:"discount_percent"`
+}
+
+// passengerTypes defines the tiers in presentation order. Pricing, the AI
+// function schema, the system prompt and request validation all derive from
+// this slice, so a new tier only needs an entry here.
+var passengerTypes = []PassengerTypeDefinition{
+	{Code: "adult", Label: "Adult", Description: "full fare", DiscountPercent: 0},
+	{Code: "senior", Label: "Senior", Description: "65 and over", MinAge: intPtr(65), DiscountPercent: 20},
+	{Code: "child", Label: "Child", Description: "age 4-14", MinAge: intPtr(4), MaxAge: intPtr(14), DiscountPercent: 50},
+	{Code: "infant", Label: "Infant", Description: "age 0-3, no seat", MaxAge: intPtr(3), DiscountPercent: 100},
+}
+
+func intPtr(v int) *int { return &v }
+
+// GetAllPassengerTypes returns the passenger type definitions, copied so
+// callers cannot mutate the canonical slice.
+func GetAllPassengerTypes() []PassengerTypeDefinition {
+	out := make([]PassengerTypeDefinition, len(passengerTypes))
+	copy(out, passengerTypes)
+	return out
+}
+
+// IsValidPassengerType reports whether code names a known passenger type.
+func IsValidPassengerType(code string) bool {
+	_, ok := passengerTypeByCode(code)
+	return ok
+}
+
+func passengerTypeByCode(code string) (PassengerTypeDefinition, bool) {
+	for _, t := range passengerTypes {
+		if t.Code == code {
+			return t, true
+		}
+	}
+	return PassengerTypeDefinition{}, false
+}
+
+// passengerTypeCodes lists the codes, for enum schemas.
+func passengerTypeCodes() []string {
+	codes := make([]string, len(passengerTypes))
+	for i, t := range passengerTypes {
+		codes[i] = t.Code
+	}
+	return codes
+}
+
+// passengerTypeForAge maps an age to the matching bracketed type, falling
+// back to adult.
+func passengerTypeForAge(age int) string {
+	for _, t := range passengerTypes {
+		if t.MinAge == nil && t.MaxAge == nil {
+			continue
+		}
+		if (t.MinAge == nil || age >= *t.MinAge) && (t.MaxAge == nil || age <= *t.MaxAge) {
+			return t.Code
+		}
+	}
+	return "adult"
+}
+
+// passengerTypePromptLine renders the discounts for the system prompt, e.g.
+// "adult (full fare), senior 65 and over (20% off), ...".
+func passengerTypePromptLine() string {
+	parts := make([]string, 0, len(passengerTypes))
+	for _, t := range passengerTypes {
+		switch t.DiscountPercent {
+		case 0:
+			parts = append(parts, fmt.Sprintf("%s (%s)", t.Code, t.Description))
+		case 100:
+			parts = append(parts, fmt.Sprintf("%s (%s, free)", t.Code, t.Description))
+		default:
+			parts = append(parts, fmt.Sprintf("%s (%s, %.0f%% off)", t.Code, t.Description, t.DiscountPercent))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Payment is one recorded payment attempt against a booking.
+type Payment struct {
+	ID          int       `json:"id"`
+	BookingID   int       `json:"booking_id"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	Provider    string    `json:"provider"`
+	ProviderRef string    `json:"provider_ref"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ProcessPayment charges a booking through the mock payment gateway and
+// records the attempt. On success the booking's payment status becomes
+// 'paid'; on failure it becomes 'failed' and the booking is not confirmed.
+// The charged amount must match the booking total.
+func ProcessPayment(bookingRef string, amount float64, currency, cardToken string) (*Payment, error) {
+	var bookingID int
+	var totalPrice float64
+	var status string
+	err := db.QueryRow(
+		`SELECT id, total_price, status FROM bookings WHERE booking_ref = $1`, bookingRef,
+	).Scan(&bookingID, &totalPrice, &status)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("booking %s not found", bookingRef)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == "cancelled" {
+		return nil, fmt.Errorf("booking %s is cancelled", bookingRef)
+	}
+	if amount != totalPrice {
+		return nil, fmt.Errorf("amount %.2f does not match booking total %.2f", amount, totalPrice)
+	}
+	if currency == "" {
+		currency = "EUR"
+	}
+
+	succeeded := mockChargeCard(cardToken)
+	providerRef, err := generatePaymentRef()
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &Payment{
+		BookingID:   bookingID,
+		Amount:      amount,
+		Currency:    currency,
+		Provider:    "mock",
+		ProviderRef: providerRef,
+		Status:      "failed",
+	}
+	if succeeded {
+		payment.Status = "succeeded"
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		`INSERT INTO payments (booking_id, amount, currency, provider, provider_ref, status)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id, created_at`,
+		payment.BookingID, payment.Amount, payment.Currency,
+		payment.Provider, payment.ProviderRef, payment.Status,
+	).Scan(&payment.ID, &payment.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("recording payment: %w", err)
+	}
+
+	// Only a successful charge confirms the booking; a failed one marks it
+	// and leaves the status untouched.
+	if succeeded {
+		_, err = tx.Exec(
+			`UPDATE bookings SET payment_status = 'paid', status = 'confirmed' WHERE id = $1`,
+			bookingID,
+		)
+	} else {
+		_, err = tx.Exec(
+			`UPDATE bookings SET payment_status = 'failed' WHERE id = $1`, bookingID,
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("updating payment status: %w", err)
+	}
+	return payment, tx.Commit()
+}
+
+// mockChargeCard simulates the payment gateway: tokens ending in an even
+// digit charge successfully, everything else is declined. Deterministic, so
+// demos can show both outcomes on request.
+func mockChargeCard(cardToken string) bool {
+	if cardToken == "" {
+		return false
+	}
+	last := cardToken[len(cardToken)-1]
+	return last >= '0' && last <= '9' && (last-'0')%2 == 0
+}
+
+// generatePaymentRef produces a mock provider reference like pay_1a2b3c4d.
+func generatePaymentRef() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = bookingRefV2Alphabet[int(b)%len(bookingRefV2Alphabet)]
+	}
+	return "pay_" + string(buf), nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+)
+
+// PriceCheckLine is the per-passenger detail of a price re-check.
+type PriceCheckLine struct {
+	Name          string  `json:"name"`
+	PassengerType string  `json:"passenger_type"`
+	OldPrice      float64 `json:"old_price"`
+	NewPrice      float64 `json:"new_price"`
+}
+
+// PriceCheckReport compares what a booking cost against what it would cost
+// under the current fare rules, without modifying anything.
+type PriceCheckReport struct {
+	BookingRef         string           `json:"booking_ref"`
+	OldTotal           float64          `json:"old_total"`
+	NewTotal           float64          `json:"new_total"`
+	Difference         float64          `json:"difference"`
+	PassengerBreakdown []PriceCheckLine `json:"passenger_breakdown"`
+	WouldRequireRefund bool             `json:"would_require_refund"`
+}
+
+// RecalculateBookingPrice re-prices an existing booking with the current
+// schedule base price, discount rates and promo rules, and returns the old
+// and new totals. The booking is not modified.
+func RecalculateBookingPrice(bookingRef string) (oldPrice, newPrice float64, err error) {
+	report, err := CheckBookingPrice(bookingRef)
+	if err != nil {
+		return 0, 0, err
+	}
+	return report.OldTotal, report.NewTotal, nil
+}
+
+// CheckBookingPrice is the detailed form of RecalculateBookingPrice, with a
+// per-passenger breakdown for the admin price-check endpoint.
+func CheckBookingPrice(bookingRef string) (*PriceCheckReport, error) {
+	booking, err := GetBooking(bookingRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var priceBase float64
+	err = db.QueryRow(
+		`SELECT price_base FROM schedules WHERE id = $1`, booking.ScheduleID,
+	).Scan(&priceBase)
+	if err != nil {
+		return nil, fmt.Errorf("loading schedule %d: %w", booking.ScheduleID, err)
+	}
+
+	report := &PriceCheckReport{BookingRef: bookingRef, OldTotal: booking.TotalPrice}
+	newTotal := 0.0
+	for _, p := range booking.Passengers {
+		line := PriceCheckLine{
+			Name:          p.Name,
+			PassengerType: p.PassengerType,
+			OldPrice:      p.Price,
+			NewPrice:      round2(CalculatePassengerPrice(priceBase, p.PassengerType)),
+		}
+		newTotal += line.NewPrice
+		report.PassengerBreakdown = append(report.PassengerBreakdown, line)
+	}
+
+	if len(booking.Passengers) >= cfg.GroupDiscountThreshold {
+		newTotal *= 0.90
+	}
+	if discount, ok := promoCodes[booking.PromoCode]; ok {
+		newTotal *= 1 - discount
+	}
+
+	report.NewTotal = round2(newTotal)
+	report.Difference = round2(report.NewTotal - report.OldTotal)
+	report.WouldRequireRefund = report.Difference < 0
+	return report, nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+
+	"trainbooking/models"
+)
+
+// minSchedulePrice is the floor bulk adjustments can never push a fare
+// below.
+const minSchedulePrice = 1.00
+
+// ScheduleFilter selects the schedules a bulk operation applies to. Zero
+// values mean "any"; DayOfWeek is a pointer because 0 (Sunday) is a valid
+// filter value.
+type ScheduleFilter struct {
+	TrainType     string `json:"train_type"`
+	OriginID      int    `json:"origin_id"`
+	DestinationID int    `json:"destination_id"`
+	DayOfWeek     *int   `json:"day_of_week"`
+}
+
+// BulkPriceUpdateRequest describes one bulk price adjustment: which
+// schedules, and how their price_base changes. "percent" multiplies (10 =
+// +10%, -10 = -10%), "fixed" adds a euro amount.
+type BulkPriceUpdateRequest struct {
+	Filter          ScheduleFilter `json:"filter"`
+	AdjustmentType  string         `json:"adjustment_type"`
+	AdjustmentValue float64        `json:"adjustment_value"`
+	// DryRun reports what would change without committing anything.
+	DryRun bool `json:"dry_run"`
+}
+
+// BulkPriceUpdateResult summarises a bulk price update, with up to 10
+// sample schedules showing their (would-be) new prices.
+type BulkPriceUpdateResult struct {
+	UpdatedCount    int               `json:"updated_count"`
+	DryRun          bool              `json:"dry_run"`
+	SampleSchedules []models.Schedule `json:"sample_schedules"`
+}
+
+// BulkUpdatePrices applies a price adjustment to every active schedule
+// matching the filter, in one transaction, flooring each fare at EUR 1.00.
+// In dry-run mode nothing is written and the samples show the prices the
+// update would have produced.
+func BulkUpdatePrices(req BulkPriceUpdateRequest) (*BulkPriceUpdateResult, error) {
+	if req.AdjustmentType != "percent" && req.AdjustmentType != "fixed" {
+		return nil, fmt.Errorf("invalid adjustment_type %q: must be percent or fixed", req.AdjustmentType)
+	}
+
+	query := `
+		SELECT s.id, s.train_id, t.number, t.type, s.origin_id, s.destination_id,
+		       o.name, d.name,
+		       to_char(s.departure_time, 'HH24:MI'), to_char(s.arrival_time, 'HH24:MI'),
+		       s.day_of_week, s.price_base, s.total_seats, s.available_seats, s.is_active
+		FROM schedules s
+		JOIN trains t ON t.id = s.train_id
+		JOIN stations o ON o.id = s.origin_id
+		JOIN stations d ON d.id = s.destination_id
+		WHERE s.is_active`
+	var args []interface{}
+	if req.Filter.TrainType != "" {
+		query += fmt.Sprintf(" AND t.type = $%d", len(args)+1)
+		args = append(args, req.Filter.TrainType)
+	}
+	if req.Filter.OriginID != 0 {
+		query += fmt.Sprintf(" AND s.origin_id = $%d", len(args)+1)
+		args = append(args, req.Filter.OriginID)
+	}
+	if req.Filter.DestinationID != 0 {
+		query += fmt.Sprintf(" AND s.destination_id = $%d", len(args)+1)
+		args = append(args, req.Filter.DestinationID)
+	}
+	if req.Filter.DayOfWeek != nil {
+		query += fmt.Sprintf(" AND s.day_of_week = $%d", len(args)+1)
+		args = append(args, *req.Filter.DayOfWeek)
+	}
+	query += " ORDER BY s.id"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("selecting schedules for price update: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []models.Schedule
+	for rows.Next() {
+		var s models.Schedule
+		if err := rows.Scan(&s.ID, &s.TrainID, &s.TrainNumber, &s.TrainType,
+			&s.OriginID, &s.DestinationID, &s.Origin, &s.Destination,
+			&s.DepartureTime, &s.ArrivalTime, &s.DayOfWeek, &s.PriceBase,
+			&s.TotalSeats, &s.AvailableSeats, &s.IsActive,
+		); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &BulkPriceUpdateResult{
+		UpdatedCount:    len(schedules),
+		DryRun:          req.DryRun,
+		SampleSchedules: []models.Schedule{},
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for i := range schedules {
+		newPrice := schedules[i].PriceBase
+		switch req.AdjustmentType {
+		case "percent":
+			newPrice *= 1 + req.AdjustmentValue/100
+		case "fixed":
+			newPrice += req.AdjustmentValue
+		}
+		newPrice = round2(newPrice)
+		if newPrice < minSchedulePrice {
+			newPrice = minSchedulePrice
+		}
+		schedules[i].PriceBase = newPrice
+
+		if !req.DryRun {
+			if _, err := tx.Exec(
+				`UPDATE schedules SET price_base = $1 WHERE id = $2`,
+				newPrice, schedules[i].ID,
+			); err != nil {
+				return nil, fmt.Errorf("updating schedule %d: %w", schedules[i].ID, err)
+			}
+		}
+		if len(result.SampleSchedules) < 10 {
+			result.SampleSchedules = append(result.SampleSchedules, schedules[i])
+		}
+	}
+	if req.DryRun {
+		return result, nil
+	}
+	return result, tx.Commit()
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// activeProviderValue holds the provider currently serving new chat turns.
+// It starts as cfg.AIProvider and can be switched at runtime without a
+// restart; turns already in flight finish on the provider they started with.
+var activeProviderValue atomic.Value // string
+
+// activeProvider returns the provider new conversations should use.
+func activeProvider() string {
+	if p, ok := activeProviderValue.Load().(string); ok && p != "" {
+		return p
+	}
+	return cfg.AIProvider
+}
+
+// ActiveAIProvider exposes the current provider for handlers and status
+// endpoints.
+func ActiveAIProvider() string {
+	return activeProvider()
+}
+
+// MigrateAIProvider switches the active AI provider at runtime. The new
+// provider is checked for basic reachability first, and the switch is
+// recorded in provider_changes.
+func MigrateAIProvider(fromProvider, toProvider, reason string) error {
+	if err := checkProviderReachable(toProvider); err != nil {
+		return err
+	}
+
+	activeProviderValue.Store(toProvider)
+
+	_, err := db.Exec(
+		`INSERT INTO provider_changes (from_provider, to_provider, reason)
+		 VALUES ($1, $2, NULLIF($3, ''))`,
+		fromProvider, toProvider, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("recording provider change: %w", err)
+	}
+	return nil
+}
+
+// checkProviderReachable verifies a provider can plausibly serve requests:
+// hosted providers need their API key configured, Ollama must answer on its
+// URL. It deliberately avoids a paid test completion.
+func checkProviderReachable(provider string) error {
+	switch provider {
+	case "openai":
+		if cfg.OpenAIAPIKey == "" {
+			return fmt.Errorf("OPENAI_API_KEY is not configured")
+		}
+	case "anthropic":
+		if cfg.AnthropicAPIKey == "" {
+			return fmt.Errorf("ANTHROPIC_API_KEY is not configured")
+		}
+	case "ollama":
+		client := &http.Client{Timeout: 5 * time.Second}
+		resp, err := client.Get(cfg.OllamaURL + "/api/tags")
+		if err != nil {
+			return fmt.Errorf("Ollama not reachable at %s: %w", cfg.OllamaURL, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("Ollama at %s answered %d", cfg.OllamaURL, resp.StatusCode)
+		}
+	default:
+		return fmt.Errorf("unknown AI provider %q", provider)
+	}
+	return nil
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"trainbooking/models"
+)
+
+var aiHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxToolRounds bounds the function-call loop so a misbehaving model cannot
+// keep calling functions forever.
+const maxToolRounds = 5
+
+// completeText sends the conversation to the configured provider without any
+// tools attached, for internal tasks like summarisation where function calls
+// make no sense.
+func completeText(ctx context.Context, messages []models.ChatMessage) (string, error) {
+	switch provider := activeProvider(); provider {
+	case "openai":
+		return completeTextOpenAI(ctx, messages)
+	case "anthropic":
+		return completeTextAnthropic(ctx, messages)
+	case "ollama":
+		return callOllama(ctx, messages)
+	default:
+		return "", fmt.Errorf("unknown AI provider %q", provider)
+	}
+}
+
+// --- OpenAI -----------------------------------------------------------
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// callOpenAI sends the conversation to the OpenAI chat completions API with
+// the booking functions attached as tools. Tool calls are executed locally
+// and their results resubmitted until the model produces a text reply.
+func callOpenAI(ctx context.Context, sessionID string, messages []models.ChatMessage) (string, *models.FunctionCall, error) {
+	tools := make([]map[string]interface{}, 0, len(getFunctionDefinitions()))
+	for _, f := range getFunctionDefinitions() {
+		tools = append(tools, map[string]interface{}{"type": "function", "function": f})
+	}
+
+	conversation := make([]openAIMessage, 0, len(messages))
+	for _, m := range messages {
+		conversation = append(conversation, openAIMessage{Role: m.Role, Content: m.Content})
+	}
+
+	var lastCall *models.FunctionCall
+	for round := 0; round < maxToolRounds; round++ {
+		body, err := json.Marshal(map[string]interface{}{
+			"model":    cfg.OpenAIModel,
+			"messages": conversation,
+			"tools":    tools,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewReader(body))
+		if err != nil {
+			return "", nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+cfg.OpenAIAPIKey)
+
+		resp, err := aiHTTPClient.Do(req)
+		if err != nil {
+			return "", nil, fmt.Errorf("calling OpenAI: %w", err)
+		}
+
+		var parsed openAIResponse
+		err = json.NewDecoder(resp.Body).Decode(&parsed)
+		resp.Body.Close()
+		if err != nil {
+			return "", nil, fmt.Errorf("decoding OpenAI response: %w", err)
+		}
+		if parsed.Error != nil {
+			return "", nil, fmt.Errorf("OpenAI error: %s", parsed.Error.Message)
+		}
+		if len(parsed.Choices) == 0 {
+			return "", nil, fmt.Errorf("OpenAI returned no choices")
+		}
+
+		msg := parsed.Choices[0].Message
+		if len(msg.ToolCalls) == 0 {
+			return msg.Content, lastCall, nil
+		}
+
+		conversation = append(conversation, msg)
+		for _, call := range msg.ToolCalls {
+			var args map[string]interface{}
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+				args = map[string]interface{}{}
+			}
+			lastCall = &models.FunctionCall{Name: call.Function.Name, Arguments: args}
+			result := executeFunction(sessionID, call.Function.Name, args)
+			conversation = append(conversation, openAIMessage{
+				Role:       "tool",
+				Content:    result,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+	return "", lastCall, fmt.Errorf("too many function-call rounds")
+}
+
+// completeTextOpenAI is the no-tools variant of callOpenAI: one request, one
+// text reply.
+func completeTextOpenAI(ctx context.Context, messages []models.ChatMessage) (string, error) {
+	conversation := make([]openAIMessage, 0, len(messages))
+	for _, m := range messages {
+		conversation = append(conversation, openAIMessage{Role: m.Role, Content: m.Content})
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"model":    cfg.OpenAIModel,
+		"messages": conversation,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+cfg.OpenAIAPIKey)
+
+	resp, err := aiHTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("calling OpenAI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var parsed openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("decoding OpenAI response: %w", err)
+	}
+	if parsed.Error != nil {
+		return "", fmt.Errorf("OpenAI error: %s", parsed.Error.Message)
+	}
+	if len(parsed.Choices) == 0 {
+		return "", fmt.Errorf("OpenAI returned no choices")
+	}
+	return parsed.Choices[0].Message.Content, nil
+}
+
+// --- Anthropic --------------------------------------------------------
+
+type anthropicMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"` // string or []anthropicContentBlock
+}
+
+type anthropicContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+	// tool_use blocks (assistant)
+	ID    string                 `json:"id,omitempty"`
+	Name  string                 `json:"name,omitempty"`
+	Input map[string]interface{} `json:"input,omitempty"`
+	// tool_result blocks (user)
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Error      *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// callAnthropic sends the conversation to the Anthropic messages API with
+// the booking functions attached as tools (Anthropic uses input_schema in
+// place of parameters). tool_use blocks are executed locally, possibly
+// several per round, and resubmitted as tool_result blocks until the model
+// stops with a text reply — feature parity with callOpenAI.
+func callAnthropic(ctx context.Context, sessionID string, messages []models.ChatMessage) (string, *models.FunctionCall, error) {
+	tools := make([]map[string]interface{}, 0, len(getFunctionDefinitions()))
+	for _, f := range getFunctionDefinitions() {
+		tools = append(tools, map[string]interface{}{
+			"name":         f.Name,
+			"description":  f.Description,
+			"input_schema": f.Parameters,
+		})
+	}
+
+	system := ""
+	conversation := make([]anthropicMessage, 0, len(messages))
+	for _, m := range messages {
+		if m.Role == "system" {
+			system = m.Content
+			continue
+		}
+		conversation = append(conversation, anthropicMessage{Role: m.Role, Content: m.Content})
+	}
+
+	var lastCall *models.FunctionCall
+	for round := 0; round < maxToolRounds; round++ {
+		body, err := json.Marshal(map[string]interface{}{
+			"model":      cfg.AnthropicModel,
+			"max_tokens": 1024,
+			"system":     system,
+			"messages":   conversation,
+			"tools":      tools,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(body))
+		if err != nil {
+			return "", nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-api-key", cfg.AnthropicAPIKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+
+		resp, err := aiHTTPClient.Do(req)
+		if err != nil {
+			return "", nil, fmt.Errorf("calling Anthropic: %w", err)
+		}
+
+		var parsed anthropicResponse
+		err = json.NewDecoder(resp.Body).Decode(&parsed)
+		resp.Body.Close()
+		if err != nil {
+			return "", nil, fmt.Errorf("decoding Anthropic response: %w", err)
+		}
+		if parsed.Error != nil {
+			return "", nil, fmt.Errorf("Anthropic error: %s", parsed.Error.Message)
+		}
+
+		if parsed.StopReason != "tool_use" {
+			for _, block := range parsed.Content {
+				if block.Type == "text" {
+					return block.Text, lastCall, nil
+				}
+			}
+			return "", lastCall, fmt.Errorf("Anthropic returned no text content")
+		}
+
+		// Echo the assistant turn back verbatim, then answer every
+		// tool_use block with a tool_result in a single user turn.
+		conversation = append(conversation, anthropicMessage{Role: "assistant", Content: parsed.Content})
+		var results []anthropicContentBlock
+		for _, block := range parsed.Content {
+			if block.Type != "tool_use" {
+				continue
+			}
+			args := block.Input
+			if args == nil {
+				args = map[string]interface{}{}
+			}
+			lastCall = &models.FunctionCall{Name: block.Name, Arguments: args}
+			results = append(results, anthropicContentBlock{
+				Type:      "tool_result",
+				ToolUseID: block.ID,
+				Content:   executeFunction(sessionID, block.Name, args),
+			})
+		}
+		conversation = append(conversation, anthropicMessage{Role: "user", Content: results})
+	}
+	return "", lastCall, fmt.Errorf("too many function-call rounds")
+}
+
+// completeTextAnthropic is the no-tools variant of callAnthropic: one
+// request, one text reply.
+func completeTextAnthropic(ctx context.Context, messages []models.ChatMessage) (string, error) {
+	system := ""
+	conversation := make([]anthropicMessage, 0, len(messages))
+	for _, m := range messages {
+		if m.Role == "system" {
+			system = m.Content
+			continue
+		}
+		conversation = append(conversation, anthropicMessage{Role: m.Role, Content: m.Content})
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"model":      cfg.AnthropicModel,
+		"max_tokens": 1024,
+		"system":     system,
+		"messages":   conversation,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", cfg.AnthropicAPIKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := aiHTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("calling Anthropic: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var parsed anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("decoding Anthropic response: %w", err)
+	}
+	if parsed.Error != nil {
+		return "", fmt.Errorf("Anthropic error: %s", parsed.Error.Message)
+	}
+	for _, block := range parsed.Content {
+		if block.Type == "text" {
+			return block.Text, nil
+		}
+	}
+	return "", fmt.Errorf("Anthropic returned no text content")
+}
+
+// --- Ollama -----------------------------------------------------------
+
+type ollamaResponse struct {
+	Message models.ChatMessage `json:"message"`
+	Error   string             `json:"error"`
+}
+
+// callOllama sends the conversation to a local Ollama instance. This path
+// is text-only. Cancelling ctx aborts the request and makes the body read
+// fail instead of blocking.
+func callOllama(ctx context.Context, messages []models.ChatMessage) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":    cfg.OllamaModel,
+		"messages": messages,
+		"stream":   false,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cfg.OllamaURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := aiHTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("calling Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var parsed ollamaResponse
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return "", fmt.Errorf("decoding Ollama response: %w", err)
+	}
+	if parsed.Error != "" {
+		return "", fmt.Errorf("Ollama error: %s", parsed.Error)
+	}
+	return parsed.Message.Content, nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"trainbooking/models"
+)
+
+// PartialRefundCheck reports whether individual passengers can be removed
+// from a booking and how much money that could return at most.
+type PartialRefundCheck struct {
+	EligibleForPartialRefund bool    `json:"eligible_for_partial_refund"`
+	Reason                   string  `json:"reason,omitempty"`
+	MaxRefundablePassengers  int     `json:"max_refundable_passengers"`
+	RefundableAmount         float64 `json:"refundable_amount"`
+}
+
+// CheckPartialRefundEligibility decides whether a booking qualifies for
+// removing individual passengers: it must be confirmed, carry more than one
+// non-infant passenger (one must always remain), and depart more than six
+// hours from now. RefundableAmount is the most that could come back —
+// everything except the cheapest remaining non-infant fare.
+func CheckPartialRefundEligibility(bookingRef string) (PartialRefundCheck, error) {
+	booking, err := GetBooking(bookingRef)
+	if err != nil {
+		return PartialRefundCheck{}, err
+	}
+	return checkPartialRefund(booking), nil
+}
+
+func checkPartialRefund(booking *models.Booking) PartialRefundCheck {
+	if booking.Status != "confirmed" {
+		return PartialRefundCheck{Reason: fmt.Sprintf("booking is %s", booking.Status)}
+	}
+
+	nonInfant := 0
+	cheapest := 0.0
+	total := 0.0
+	for _, p := range booking.Passengers {
+		total += p.Price
+		if p.PassengerType == "infant" {
+			continue
+		}
+		if nonInfant == 0 || p.Price < cheapest {
+			cheapest = p.Price
+		}
+		nonInfant++
+	}
+	if nonInfant <= 1 {
+		return PartialRefundCheck{Reason: "booking has only one non-infant passenger"}
+	}
+	if time.Until(departureTimestamp(booking)) <= 6*time.Hour {
+		return PartialRefundCheck{Reason: "departure is less than 6 hours away"}
+	}
+
+	return PartialRefundCheck{
+		EligibleForPartialRefund: true,
+		MaxRefundablePassengers:  nonInfant - 1,
+		RefundableAmount:         round2(total - cheapest),
+	}
+}
+
+// RemovePassenger takes one passenger off a confirmed booking, refunds their
+// fare and recalculates the booking total. The booking must pass the partial
+// refund eligibility check; infants can be removed alongside but never leave
+// a booking without a non-infant passenger.
+func RemovePassenger(bookingRef string, passengerID int) (*models.Booking, error) {
+	booking, err := GetBooking(bookingRef)
+	if err != nil {
+		return nil, err
+	}
+	check := checkPartialRefund(booking)
+	if !check.EligibleForPartialRefund {
+		return nil, fmt.Errorf("partial refund not available: %s", check.Reason)
+	}
+
+	var removed *models.Passenger
+	for i := range booking.Passengers {
+		if booking.Passengers[i].ID == passengerID {
+			removed = &booking.Passengers[i]
+			break
+		}
+	}
+	if removed == nil {
+		return nil, fmt.Errorf("passenger %d is not on booking %s", passengerID, bookingRef)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM passengers WHERE id = $1`, passengerID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(
+		`UPDATE bookings
+		 SET total_price = total_price - $1,
+		     passenger_count = passenger_count - 1,
+		     refund_amount = refund_amount + $1
+		 WHERE booking_ref = $2`,
+		removed.Price, bookingRef,
+	); err != nil {
+		return nil, err
+	}
+	if removed.PassengerType != "infant" {
+		if _, err := tx.Exec(
+			`UPDATE schedules SET available_seats = available_seats + 1 WHERE id = $1`,
+			booking.ScheduleID,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if err := recordBookingEvent(tx, bookingRef, "passenger_removed", map[string]interface{}{
+		"passenger_id": passengerID, "name": removed.Name, "refund_amount": removed.Price,
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return GetBooking(bookingRef)
+}
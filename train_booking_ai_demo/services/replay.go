@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"trainbooking/models"
+)
+
+// ReplayTurn pairs one original user message with the response the current
+// provider produced for it during a replay.
+type ReplayTurn struct {
+	UserMessage  string               `json:"user_message"`
+	Response     string               `json:"response"`
+	FunctionCall *models.FunctionCall `json:"function_call,omitempty"`
+}
+
+// FunctionCallDiff compares the function call of one turn between the
+// original conversation and its replay. Original and Replay are rendered as
+// "name(arguments)"; an empty string means that side made no call.
+type FunctionCallDiff struct {
+	Turn     int    `json:"turn"`
+	Original string `json:"original,omitempty"`
+	Replay   string `json:"replay,omitempty"`
+	Match    bool   `json:"match"`
+}
+
+// ReplayReport is the outcome of replaying a conversation through the
+// current AI provider.
+type ReplayReport struct {
+	SessionID       string             `json:"session_id"`
+	ReplaySessionID string             `json:"replay_session_id,omitempty"`
+	DryRun          bool               `json:"dry_run"`
+	Provider        string             `json:"provider"`
+	Turns           []ReplayTurn       `json:"turns"`
+	FunctionCalls   []FunctionCallDiff `json:"function_calls"`
+}
+
+// ReplayConversation re-runs every user message of a stored session through
+// the current AI provider, so support can verify that a provider change
+// would have produced the same outcomes. In dry-run mode nothing is
+// persisted; otherwise the replies are saved under a new session with the
+// "_replay" suffix. Function calls the replay makes are executed for real
+// either way — replay bookings land on the replay session.
+func ReplayConversation(ctx context.Context, sessionID string, dryRun bool) (*ReplayReport, error) {
+	userMessages, err := sessionUserMessages(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(userMessages) == 0 {
+		return nil, fmt.Errorf("session %s has no user messages", sessionID)
+	}
+	originalCalls, err := sessionFunctionCalls(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	replaySessionID := sessionID + "_replay"
+	provider := activeProvider()
+	report := &ReplayReport{
+		SessionID: sessionID,
+		DryRun:    dryRun,
+		Provider:  provider,
+	}
+	if !dryRun {
+		report.ReplaySessionID = replaySessionID
+	}
+
+	conversation := []models.ChatMessage{{Role: "system", Content: buildSystemPrompt()}}
+	var replayCalls []*models.FunctionCall
+	for _, userMessage := range userMessages {
+		conversation = append(conversation, models.ChatMessage{Role: "user", Content: userMessage})
+
+		var reply string
+		var call *models.FunctionCall
+		switch provider {
+		case "openai":
+			reply, call, err = callOpenAI(ctx, replaySessionID, conversation)
+		case "anthropic":
+			reply, call, err = callAnthropic(ctx, replaySessionID, conversation)
+		case "ollama":
+			reply, err = callOllama(ctx, conversation)
+		default:
+			err = fmt.Errorf("unknown AI provider %q", provider)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("replaying turn %d: %w", len(report.Turns)+1, err)
+		}
+
+		conversation = append(conversation, models.ChatMessage{Role: "assistant", Content: reply})
+		report.Turns = append(report.Turns, ReplayTurn{
+			UserMessage:  userMessage,
+			Response:     reply,
+			FunctionCall: call,
+		})
+		replayCalls = append(replayCalls, call)
+
+		if !dryRun {
+			if err := SaveConversationMessage(replaySessionID, "user", userMessage, nil); err != nil {
+				return nil, fmt.Errorf("saving replay message: %w", err)
+			}
+			if err := SaveConversationMessage(replaySessionID, "assistant", reply, call); err != nil {
+				return nil, fmt.Errorf("saving replay message: %w", err)
+			}
+		}
+	}
+
+	report.FunctionCalls = diffFunctionCalls(originalCalls, replayCalls)
+	return report, nil
+}
+
+// sessionUserMessages returns all user messages of a session in
+// chronological order.
+func sessionUserMessages(sessionID string) ([]string, error) {
+	rows, err := db.Query(
+		`SELECT message FROM conversation_history
+		 WHERE session_id = $1 AND role = 'user' ORDER BY timestamp`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []string
+	for rows.Next() {
+		var m string
+		if err := rows.Scan(&m); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// sessionFunctionCalls returns the function call of each assistant turn of a
+// session in chronological order, nil for turns without one.
+func sessionFunctionCalls(sessionID string) ([]*models.FunctionCall, error) {
+	rows, err := db.Query(
+		`SELECT function_call FROM conversation_history
+		 WHERE session_id = $1 AND role = 'assistant' ORDER BY timestamp`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var calls []*models.FunctionCall
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		if raw == nil {
+			calls = append(calls, nil)
+			continue
+		}
+		var call models.FunctionCall
+		if err := json.Unmarshal(raw, &call); err != nil {
+			return nil, fmt.Errorf("decoding function call: %w", err)
+		}
+		calls = append(calls, &call)
+	}
+	return calls, rows.Err()
+}
+
+// diffFunctionCalls compares the function calls of the original and replayed
+// conversations turn by turn. Turns where neither side called a function are
+// omitted from the report.
+func diffFunctionCalls(original, replay []*models.FunctionCall) []FunctionCallDiff {
+	n := len(original)
+	if len(replay) > n {
+		n = len(replay)
+	}
+	diffs := []FunctionCallDiff{}
+	for i := 0; i < n; i++ {
+		var before, after *models.FunctionCall
+		if i < len(original) {
+			before = original[i]
+		}
+		if i < len(replay) {
+			after = replay[i]
+		}
+		if before == nil && after == nil {
+			continue
+		}
+		diffs = append(diffs, FunctionCallDiff{
+			Turn:     i + 1,
+			Original: formatFunctionCall(before),
+			Replay:   formatFunctionCall(after),
+			Match:    formatFunctionCall(before) == formatFunctionCall(after),
+		})
+	}
+	return diffs
+}
+
+// formatFunctionCall renders a call as "name(arguments)" for comparison, or
+// "" for nil. Arguments marshal with sorted keys, so equal calls render
+// identically.
+func formatFunctionCall(call *models.FunctionCall) string {
+	if call == nil {
+		return ""
+	}
+	args, err := json.Marshal(call.Arguments)
+	if err != nil {
+		return call.Name
+	}
+	return fmt.Sprintf("%s(%s)", call.Name, args)
+}
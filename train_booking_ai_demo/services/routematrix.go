@@ -0,0 +1,71 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// RouteInfo is one cell of the route-comparison matrix: the cheapest direct
+// option between an ordered pair of stations, if any exists.
+type RouteInfo struct {
+	OriginCode      string  `json:"origin_code"`
+	DestinationCode string  `json:"destination_code"`
+	Exists          bool    `json:"exists"`
+	MinPrice        float64 `json:"min_price,omitempty"`
+	Duration        string  `json:"duration,omitempty"`
+}
+
+// GetRouteMatrix builds an N×N grid over the given station codes (max 10).
+// Each off-diagonal cell holds the cheapest active schedule with seats left
+// over the coming week; cells with no direct route have Exists false, and so
+// do the diagonal ones.
+func GetRouteMatrix(stations []string) ([][]RouteInfo, error) {
+	if len(stations) < 2 {
+		return nil, fmt.Errorf("at least 2 station codes are required")
+	}
+	if len(stations) > 10 {
+		return nil, fmt.Errorf("at most 10 station codes are allowed, got %d", len(stations))
+	}
+
+	matrix := make([][]RouteInfo, len(stations))
+	for i, origin := range stations {
+		matrix[i] = make([]RouteInfo, len(stations))
+		for j, destination := range stations {
+			cell := RouteInfo{OriginCode: origin, DestinationCode: destination}
+			if i != j {
+				if err := fillRouteCell(&cell); err != nil {
+					return nil, err
+				}
+			}
+			matrix[i][j] = cell
+		}
+	}
+	return matrix, nil
+}
+
+// fillRouteCell looks up the cheapest bookable schedule for one ordered
+// station pair. A week-long window covers every day_of_week, so the query
+// only needs availability and the is_active flag.
+func fillRouteCell(cell *RouteInfo) error {
+	var departure, arrival string
+	err := db.QueryRow(`
+		SELECT s.price_base,
+		       to_char(s.departure_time, 'HH24:MI'), to_char(s.arrival_time, 'HH24:MI')
+		FROM schedules s
+		JOIN stations o ON o.id = s.origin_id
+		JOIN stations d ON d.id = s.destination_id
+		WHERE o.code = $1 AND d.code = $2 AND s.is_active AND s.available_seats > 0
+		ORDER BY s.price_base
+		LIMIT 1`,
+		cell.OriginCode, cell.DestinationCode,
+	).Scan(&cell.MinPrice, &departure, &arrival)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("looking up route %s-%s: %w", cell.OriginCode, cell.DestinationCode, err)
+	}
+	cell.Exists = true
+	cell.Duration = formatDuration(departure, arrival)
+	return nil
+}
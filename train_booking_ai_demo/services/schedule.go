@@ -0,0 +1,516 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"trainbooking/models"
+)
+
+// ScheduleConflictError reports which existing schedule overlaps a new one,
+// so handlers can surface the id alongside a 409.
+type ScheduleConflictError struct {
+	ScheduleID int
+}
+
+func (e *ScheduleConflictError) Error() string {
+	return fmt.Sprintf("schedule conflicts with existing schedule %d", e.ScheduleID)
+}
+
+// ScheduleValidationError is one invalid field of a schedule request. The
+// admin UI gets all of them at once instead of fixing one at a time.
+type ScheduleValidationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	// ConflictScheduleID is set only on the time-conflict error.
+	ConflictScheduleID int `json:"conflict_with_schedule_id,omitempty"`
+}
+
+// ScheduleValidationErrors aggregates validation failures into a single
+// error value for callers that only care that validation failed.
+type ScheduleValidationErrors []ScheduleValidationError
+
+func (e ScheduleValidationErrors) Error() string {
+	if len(e) == 1 {
+		return fmt.Sprintf("%s: %s", e[0].Field, e[0].Message)
+	}
+	return fmt.Sprintf("%d invalid schedule fields", len(e))
+}
+
+// ValidateSchedule checks every constraint on a schedule request — field
+// ranges, referenced rows, and time conflicts with the same train's other
+// schedules — and returns all violations, not just the first. excludeID
+// ignores one schedule in the conflict check (the one being updated); pass 0
+// when creating.
+func ValidateSchedule(req models.ScheduleCreateRequest, excludeID int) ([]ScheduleValidationError, error) {
+	var errs []ScheduleValidationError
+	add := func(field, message string) {
+		errs = append(errs, ScheduleValidationError{Field: field, Message: message})
+	}
+
+	dep, depErr := time.Parse("15:04", req.DepartureTime)
+	if depErr != nil {
+		add("departure_time", fmt.Sprintf("invalid time %q: use HH:MM", req.DepartureTime))
+	}
+	arr, arrErr := time.Parse("15:04", req.ArrivalTime)
+	if arrErr != nil {
+		add("arrival_time", fmt.Sprintf("invalid time %q: use HH:MM", req.ArrivalTime))
+	}
+	if depErr == nil && arrErr == nil && !arr.After(dep) {
+		add("arrival_time", "must be after departure_time")
+	}
+	if req.DayOfWeek < 0 || req.DayOfWeek > 6 {
+		add("day_of_week", "must be 0-6")
+	}
+	if req.PriceBase <= 0 {
+		add("price_base", "must be positive")
+	}
+	if req.TotalSeats < 1 {
+		add("total_seats", "must be at least 1")
+	}
+	if req.OriginID == req.DestinationID {
+		add("destination_id", "must differ from origin_id")
+	}
+
+	if exists, err := rowExists("trains", req.TrainID); err != nil {
+		return nil, err
+	} else if !exists {
+		add("train_id", fmt.Sprintf("train %d not found", req.TrainID))
+	}
+	if exists, err := rowExists("stations", req.OriginID); err != nil {
+		return nil, err
+	} else if !exists {
+		add("origin_id", fmt.Sprintf("station %d not found", req.OriginID))
+	}
+	if exists, err := rowExists("stations", req.DestinationID); err != nil {
+		return nil, err
+	} else if !exists {
+		add("destination_id", fmt.Sprintf("station %d not found", req.DestinationID))
+	}
+
+	if req.PriceBase > 0 && req.OriginID != req.DestinationID {
+		estimate, err := distanceBasedPriceEstimate(req.TrainID, req.OriginID, req.DestinationID)
+		if err != nil {
+			return nil, err
+		}
+		if estimate > 0 && (req.PriceBase < 0.8*estimate || req.PriceBase > 1.2*estimate) {
+			add("price_base", fmt.Sprintf(
+				"%.2f is more than 20%% away from the distance-based estimate of %.2f", req.PriceBase, estimate))
+		}
+	}
+
+	if depErr == nil && arrErr == nil && arr.After(dep) {
+		conflictID, err := findConflictingSchedule(req.TrainID, dep, arr, req.DayOfWeek)
+		if err != nil {
+			return nil, err
+		}
+		if conflictID != 0 && conflictID != excludeID {
+			errs = append(errs, ScheduleValidationError{
+				Field:              "departure_time",
+				Message:            fmt.Sprintf("conflicts with existing schedule %d", conflictID),
+				ConflictScheduleID: conflictID,
+			})
+		}
+	}
+	return errs, nil
+}
+
+// rowExists reports whether a table has a row with the given id. table is
+// always a literal in callers, never user input.
+func rowExists(table string, id int) (bool, error) {
+	var exists bool
+	err := db.QueryRow(
+		fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)`, table), id,
+	).Scan(&exists)
+	return exists, err
+}
+
+// CreateSchedule validates and inserts a new weekly schedule. available_seats
+// starts equal to total_seats.
+func CreateSchedule(req models.ScheduleCreateRequest) (*models.Schedule, error) {
+	if err := validateScheduleOrFail(req, 0); err != nil {
+		return nil, err
+	}
+	dep, _ := time.Parse("15:04", req.DepartureTime)
+	arr, _ := time.Parse("15:04", req.ArrivalTime)
+
+	var id int
+	err := db.QueryRow(`
+		INSERT INTO schedules
+			(train_id, origin_id, destination_id, departure_time, arrival_time,
+			 day_of_week, price_base, total_seats, available_seats, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8, TRUE)
+		RETURNING id`,
+		req.TrainID, req.OriginID, req.DestinationID,
+		dep.Format("15:04:05"), arr.Format("15:04:05"),
+		req.DayOfWeek, req.PriceBase, req.TotalSeats,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("inserting schedule: %w", err)
+	}
+	return GetSchedule(id)
+}
+
+// UpdateSchedule replaces the mutable fields of an existing schedule. Seats
+// already sold are preserved: available_seats is adjusted by the change in
+// total_seats, floored at zero. Price changes are recorded in
+// schedule_price_history, attributed to changedByUserID (0 for unattributed
+// callers).
+func UpdateSchedule(id int, req models.ScheduleCreateRequest, changedByUserID int) (*models.Schedule, error) {
+	if err := validateScheduleOrFail(req, id); err != nil {
+		return nil, err
+	}
+	dep, _ := time.Parse("15:04", req.DepartureTime)
+	arr, _ := time.Parse("15:04", req.ArrivalTime)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var oldPrice float64
+	err = tx.QueryRow(
+		`SELECT price_base FROM schedules WHERE id = $1 AND is_active FOR UPDATE`, id,
+	).Scan(&oldPrice)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("schedule %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if oldPrice != req.PriceBase {
+		_, err = tx.Exec(
+			`INSERT INTO schedule_price_history (schedule_id, old_price, new_price, changed_by_user_id)
+			 VALUES ($1, $2, $3, NULLIF($4, 0))`,
+			id, oldPrice, req.PriceBase, changedByUserID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("recording price change for schedule %d: %w", id, err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE schedules SET
+			train_id = $1, origin_id = $2, destination_id = $3,
+			departure_time = $4, arrival_time = $5, day_of_week = $6,
+			price_base = $7,
+			available_seats = GREATEST(0, available_seats + $8 - total_seats),
+			total_seats = $8
+		WHERE id = $9`,
+		req.TrainID, req.OriginID, req.DestinationID,
+		dep.Format("15:04:05"), arr.Format("15:04:05"),
+		req.DayOfWeek, req.PriceBase, req.TotalSeats, id)
+	if err != nil {
+		return nil, fmt.Errorf("updating schedule %d: %w", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return GetSchedule(id)
+}
+
+// UpdateSchedulePricing changes only a schedule's price_base, recording the
+// change in schedule_price_history. It is the safe path for price-only edits:
+// unlike UpdateSchedule it cannot accidentally touch times or seats.
+func UpdateSchedulePricing(scheduleID int, newPriceBase float64, changedBy int) error {
+	if newPriceBase <= 0 {
+		return fmt.Errorf("price_base must be positive")
+	}
+	if newPriceBase >= 10000 {
+		return fmt.Errorf("price_base %.2f exceeds the sanity cap of 10000", newPriceBase)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldPrice float64
+	err = tx.QueryRow(
+		`SELECT price_base FROM schedules WHERE id = $1 AND is_active FOR UPDATE`, scheduleID,
+	).Scan(&oldPrice)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("schedule %d not found", scheduleID)
+	}
+	if err != nil {
+		return err
+	}
+	if oldPrice == newPriceBase {
+		return nil
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO schedule_price_history (schedule_id, old_price, new_price, changed_by_user_id)
+		 VALUES ($1, $2, $3, NULLIF($4, 0))`,
+		scheduleID, oldPrice, newPriceBase, changedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("recording price change for schedule %d: %w", scheduleID, err)
+	}
+	if _, err = tx.Exec(
+		`UPDATE schedules SET price_base = $1 WHERE id = $2`, newPriceBase, scheduleID,
+	); err != nil {
+		return fmt.Errorf("updating price for schedule %d: %w", scheduleID, err)
+	}
+	return tx.Commit()
+}
+
+// PriceHistory is one recorded change of a schedule's price_base.
+type PriceHistory struct {
+	ID              int       `json:"id"`
+	ScheduleID      int       `json:"schedule_id"`
+	OldPrice        float64   `json:"old_price"`
+	NewPrice        float64   `json:"new_price"`
+	ChangedByUserID int       `json:"changed_by_user_id,omitempty"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
+
+// GetScheduleHistoricalPrices returns a schedule's price changes over the
+// last months months, newest first. months outside 1-60 falls back to 12.
+func GetScheduleHistoricalPrices(scheduleID int, months int) ([]PriceHistory, error) {
+	if months < 1 || months > 60 {
+		months = 12
+	}
+	rows, err := db.Query(`
+		SELECT id, schedule_id, old_price, new_price, COALESCE(changed_by_user_id, 0), changed_at
+		FROM schedule_price_history
+		WHERE schedule_id = $1 AND changed_at >= now() - $2 * interval '1 month'
+		ORDER BY changed_at DESC`,
+		scheduleID, months)
+	if err != nil {
+		return nil, fmt.Errorf("loading price history for schedule %d: %w", scheduleID, err)
+	}
+	defer rows.Close()
+
+	history := []PriceHistory{}
+	for rows.Next() {
+		var h PriceHistory
+		if err := rows.Scan(&h.ID, &h.ScheduleID, &h.OldPrice, &h.NewPrice,
+			&h.ChangedByUserID, &h.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// DeactivateSchedule soft-deletes a schedule so past bookings keep their
+// reference.
+func DeactivateSchedule(id int) error {
+	result, err := db.Exec(`UPDATE schedules SET is_active = FALSE WHERE id = $1 AND is_active`, id)
+	if err != nil {
+		return fmt.Errorf("deactivating schedule %d: %w", id, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("schedule %d not found", id)
+	}
+	return nil
+}
+
+// GetSchedule loads one schedule with train and station names resolved.
+func GetSchedule(id int) (*models.Schedule, error) {
+	var s models.Schedule
+	err := db.QueryRow(`
+		SELECT s.id, s.train_id, t.number, t.type, s.origin_id, s.destination_id,
+		       o.name, d.name,
+		       to_char(s.departure_time, 'HH24:MI'), to_char(s.arrival_time, 'HH24:MI'),
+		       s.day_of_week, s.price_base, s.total_seats, s.available_seats, s.is_active
+		FROM schedules s
+		JOIN trains t ON t.id = s.train_id
+		JOIN stations o ON o.id = s.origin_id
+		JOIN stations d ON d.id = s.destination_id
+		WHERE s.id = $1`, id,
+	).Scan(&s.ID, &s.TrainID, &s.TrainNumber, &s.TrainType, &s.OriginID, &s.DestinationID,
+		&s.Origin, &s.Destination, &s.DepartureTime, &s.ArrivalTime,
+		&s.DayOfWeek, &s.PriceBase, &s.TotalSeats, &s.AvailableSeats, &s.IsActive)
+	if err != nil {
+		return nil, fmt.Errorf("loading schedule %d: %w", id, err)
+	}
+	return &s, nil
+}
+
+// GetSchedulesByDayOfWeek lists the active schedules running on one weekday
+// (0 = Sunday .. 6 = Saturday), ordered by departure time.
+func GetSchedulesByDayOfWeek(dayOfWeek int) ([]models.Schedule, error) {
+	rows, err := db.Query(`
+		SELECT s.id, s.train_id, t.number, t.type, s.origin_id, s.destination_id,
+		       o.name, d.name,
+		       to_char(s.departure_time, 'HH24:MI'), to_char(s.arrival_time, 'HH24:MI'),
+		       s.day_of_week, s.price_base, s.total_seats, s.available_seats, s.is_active
+		FROM schedules s
+		JOIN trains t ON t.id = s.train_id
+		JOIN stations o ON o.id = s.origin_id
+		JOIN stations d ON d.id = s.destination_id
+		WHERE s.day_of_week = $1 AND s.is_active
+		ORDER BY s.departure_time`, dayOfWeek)
+	if err != nil {
+		return nil, fmt.Errorf("loading schedules for day %d: %w", dayOfWeek, err)
+	}
+	defer rows.Close()
+
+	schedules := []models.Schedule{}
+	for rows.Next() {
+		var s models.Schedule
+		if err := rows.Scan(&s.ID, &s.TrainID, &s.TrainNumber, &s.TrainType,
+			&s.OriginID, &s.DestinationID, &s.Origin, &s.Destination,
+			&s.DepartureTime, &s.ArrivalTime, &s.DayOfWeek, &s.PriceBase,
+			&s.TotalSeats, &s.AvailableSeats, &s.IsActive,
+		); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+// RecalculateAvailableSeats repairs a schedule's available_seats counter if
+// it has drifted from what the bookings table says: total seats minus the
+// non-infant passengers on confirmed future bookings. Returns the applied
+// delta, 0 when the counter was already correct. The schedule row stays
+// locked while recounting, so concurrent bookings cannot race the repair.
+func RecalculateAvailableSeats(scheduleID int) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var totalSeats, availableSeats int
+	err = tx.QueryRow(
+		`SELECT total_seats, available_seats FROM schedules WHERE id = $1 FOR UPDATE`,
+		scheduleID,
+	).Scan(&totalSeats, &availableSeats)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("schedule %d not found", scheduleID)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var sold int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM passengers p
+		 JOIN bookings b ON b.id = p.booking_id
+		 WHERE b.schedule_id = $1 AND b.status = 'confirmed'
+		   AND b.booking_date >= CURRENT_DATE
+		   AND p.passenger_type <> 'infant'`,
+		scheduleID,
+	).Scan(&sold)
+	if err != nil {
+		return 0, err
+	}
+
+	correct := totalSeats - sold
+	if correct < 0 {
+		correct = 0
+	}
+	delta := correct - availableSeats
+	if delta == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.Exec(
+		`UPDATE schedules SET available_seats = $1 WHERE id = $2`, correct, scheduleID,
+	); err != nil {
+		return 0, fmt.Errorf("correcting available seats: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	log.Printf("recalculated available seats for schedule %d: %d -> %d (delta %+d)",
+		scheduleID, availableSeats, correct, delta)
+	return delta, nil
+}
+
+// CopyScheduleToNewTrain recreates a schedule for a replacement train, used
+// when the original train is retired. Capacity follows the new train and
+// starts fully available. The copy goes through the usual validation, so a
+// conflict with the new train's existing schedules is rejected like any
+// other create.
+func CopyScheduleToNewTrain(scheduleID, newTrainID int) (*models.Schedule, error) {
+	source, err := GetSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalSeats int
+	err = db.QueryRow(
+		`SELECT total_seats FROM trains WHERE id = $1`, newTrainID,
+	).Scan(&totalSeats)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("train %d not found", newTrainID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateSchedule(models.ScheduleCreateRequest{
+		TrainID:       newTrainID,
+		OriginID:      source.OriginID,
+		DestinationID: source.DestinationID,
+		DepartureTime: source.DepartureTime,
+		ArrivalTime:   source.ArrivalTime,
+		DayOfWeek:     source.DayOfWeek,
+		PriceBase:     source.PriceBase,
+		TotalSeats:    totalSeats,
+	})
+}
+
+// validateScheduleOrFail runs ValidateSchedule and converts the outcome
+// into the error values handlers already understand: a lone time conflict
+// keeps its dedicated ScheduleConflictError (409), anything else becomes
+// the full ScheduleValidationErrors list (422).
+func validateScheduleOrFail(req models.ScheduleCreateRequest, excludeID int) error {
+	errs, err := ValidateSchedule(req, excludeID)
+	if err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	if len(errs) == 1 && errs[0].ConflictScheduleID != 0 {
+		return &ScheduleConflictError{ScheduleID: errs[0].ConflictScheduleID}
+	}
+	return ScheduleValidationErrors(errs)
+}
+
+// CheckScheduleConflict reports whether an active schedule already exists
+// for the same train on the same weekday whose time window overlaps the
+// given one. Windows that merely touch (arrival equal to the other
+// departure) do not conflict.
+func CheckScheduleConflict(trainID int, departureTime, arrivalTime time.Time, dayOfWeek int) (bool, error) {
+	id, err := findConflictingSchedule(trainID, departureTime, arrivalTime, dayOfWeek)
+	return id != 0, err
+}
+
+// findConflictingSchedule returns the id of a conflicting schedule, or 0 if
+// there is none. Exposed separately so callers can report which schedule
+// caused the conflict.
+func findConflictingSchedule(trainID int, departureTime, arrivalTime time.Time, dayOfWeek int) (int, error) {
+	var id int
+	err := db.QueryRow(
+		`SELECT id FROM schedules
+		 WHERE train_id = $1 AND day_of_week = $2 AND is_active
+		   AND NOT (arrival_time <= $3 OR departure_time >= $4)
+		 LIMIT 1`,
+		trainID, dayOfWeek, departureTime.Format("15:04:05"), arrivalTime.Format("15:04:05"),
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// timeWindowsOverlap is the Go-side equivalent of the SQL conflict
+// predicate: two windows overlap unless one ends before (or exactly when)
+// the other starts.
+func timeWindowsOverlap(depA, arrA, depB, arrB time.Time) bool {
+	return arrB.After(depA) && depB.Before(arrA)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func clock(hhmm string) time.Time {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestTimeWindowsOverlap(t *testing.T) {
+	tests := []struct {
+		name                   string
+		depA, arrA, depB, arrB string
+		want                   bool
+	}{
+		{"same departure time", "07:00", "10:10", "07:00", "09:00", true},
+		{"identical windows", "07:00", "10:10", "07:00", "10:10", true},
+		{"fully contained", "07:00", "10:10", "08:00", "09:00", true},
+		{"fully containing", "08:00", "09:00", "07:00", "10:10", true},
+		{"partial overlap at start", "07:00", "10:10", "06:00", "07:30", true},
+		{"partial overlap at end", "07:00", "10:10", "10:00", "11:00", true},
+		{"adjacent before (arrival == departure)", "07:00", "10:10", "05:00", "07:00", false},
+		{"adjacent after (departure == arrival)", "07:00", "10:10", "10:10", "12:00", false},
+		{"disjoint before", "07:00", "10:10", "05:00", "06:30", false},
+		{"disjoint after", "07:00", "10:10", "11:00", "12:00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := timeWindowsOverlap(clock(tt.depA), clock(tt.arrA), clock(tt.depB), clock(tt.arrB))
+			if got != tt.want {
+				t.Errorf("timeWindowsOverlap(%s-%s vs %s-%s) = %v, want %v",
+					tt.depA, tt.arrA, tt.depB, tt.arrB, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"trainbooking/models"
+)
+
+// timeWindows maps a time preference to a departure-time range.
+var timeWindows = map[string][2]string{
+	"morning":   {"05:00", "12:00"},
+	"afternoon": {"12:00", "18:00"},
+	"evening":   {"18:00", "23:59"},
+}
+
+// SearchTrains finds direct schedules between two stations on a given date
+// with enough available seats. Origin and destination accept codes, names or
+// city names (resolved with FindStationByNameOrCode).
+func SearchTrains(req models.SearchRequest) ([]models.SearchResponse, error) {
+	origin, err := FindStationByNameOrCode(req.Origin)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := FindStationByNameOrCode(req.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	travelDate, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: use YYYY-MM-DD", req.Date)
+	}
+	dayOfWeek := int(travelDate.Weekday())
+
+	passengers := req.PassengerCount
+	if passengers < 1 {
+		passengers = 1
+	}
+
+	query := `
+		SELECT s.id, t.number, t.type, o.name, d.name,
+		       to_char(s.departure_time, 'HH24:MI'), to_char(s.arrival_time, 'HH24:MI'),
+		       s.price_base, s.available_seats, t.has_wifi, t.has_food
+		FROM schedules s
+		JOIN trains t ON t.id = s.train_id
+		JOIN stations o ON o.id = s.origin_id
+		JOIN stations d ON d.id = s.destination_id
+		WHERE s.origin_id = $1 AND s.destination_id = $2
+		  AND s.day_of_week = $3 AND s.is_active
+		  AND s.available_seats >= $4
+		  AND (t.out_of_service_from IS NULL
+		       OR NOT ($5::date BETWEEN t.out_of_service_from AND t.out_of_service_until))
+		  AND NOT EXISTS (SELECT 1 FROM schedule_exceptions e
+		                  WHERE e.schedule_id = s.id AND e.exception_date = $5::date
+		                    AND e.exception_type = 'cancelled')`
+	args := []interface{}{origin.ID, destination.ID, dayOfWeek, passengers, req.Date}
+
+	if window, ok := timeWindows[req.TimePreference]; ok {
+		query += fmt.Sprintf(" AND s.departure_time BETWEEN $%d AND $%d", len(args)+1, len(args)+2)
+		args = append(args, window[0], window[1])
+	}
+	if wifi, ok := req.Filters["has_wifi"].(bool); ok && wifi {
+		query += " AND t.has_wifi"
+	}
+	if food, ok := req.Filters["has_food"].(bool); ok && food {
+		query += " AND t.has_food"
+	}
+
+	query += " ORDER BY s.departure_time"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching trains: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.SearchResponse
+	for rows.Next() {
+		var r models.SearchResponse
+		if err := rows.Scan(
+			&r.ScheduleID, &r.TrainNumber, &r.TrainType, &r.Origin, &r.Destination,
+			&r.DepartureTime, &r.ArrivalTime, &r.PricePerAdult, &r.AvailableSeats,
+			&r.HasWifi, &r.HasFood,
+		); err != nil {
+			return nil, err
+		}
+		r.Duration = formatDuration(r.DepartureTime, r.ArrivalTime)
+		r.IsAvailable = r.AvailableSeats > 0
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	annotateTimezones(results, origin, destination, travelDate)
+	if err := attachActiveAlerts(results, travelDate); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// annotateTimezones appends timezone abbreviations to the displayed times
+// when a route crosses timezones ("08:00 CET"). Same-timezone routes — the
+// whole current network — are left untouched. Timetable times are already
+// each station's local time.
+func annotateTimezones(results []models.SearchResponse, origin, destination *models.Station, travelDate time.Time) {
+	if origin.Timezone == destination.Timezone {
+		return
+	}
+	departureAbbrev := timezoneAbbrev(origin.Timezone, travelDate)
+	arrivalAbbrev := timezoneAbbrev(destination.Timezone, travelDate)
+	if departureAbbrev == "" || arrivalAbbrev == "" {
+		return
+	}
+	for i := range results {
+		results[i].DepartureTime += " " + departureAbbrev
+		results[i].ArrivalTime += " " + arrivalAbbrev
+	}
+}
+
+// timezoneAbbrev returns the abbreviation ("CET", "CEST") of an IANA
+// timezone on a given date, or "" if the timezone cannot be loaded.
+func timezoneAbbrev(name string, date time.Time) string {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return ""
+	}
+	return date.In(loc).Format("MST")
+}
+
+// attachActiveAlerts decorates search results with the advisories valid on
+// the travel date.
+func attachActiveAlerts(results []models.SearchResponse, travelDate time.Time) error {
+	scheduleIDs := make([]int, len(results))
+	for i, r := range results {
+		scheduleIDs[i] = r.ScheduleID
+	}
+	alerts, err := activeAlertsForSchedules(scheduleIDs, travelDate)
+	if err != nil {
+		return err
+	}
+	for i := range results {
+		results[i].Alerts = alerts[results[i].ScheduleID]
+	}
+	return nil
+}
+
+// SearchTrainsByArrival finds schedules into a destination that arrive no
+// later than arrivalBefore (HH:MM) on a date, for users who state when they
+// need to be there rather than when to leave. origin is optional: empty
+// matches trains from anywhere.
+func SearchTrainsByArrival(origin, destination, arrivalBefore, date string, passengerCount int) ([]models.SearchResponse, error) {
+	dest, err := FindStationByNameOrCode(destination)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := time.Parse("15:04", arrivalBefore); err != nil {
+		return nil, fmt.Errorf("invalid arrival time %q: use HH:MM", arrivalBefore)
+	}
+	travelDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: use YYYY-MM-DD", date)
+	}
+	if passengerCount < 1 {
+		passengerCount = 1
+	}
+
+	query := `
+		SELECT s.id, t.number, t.type, o.name, d.name,
+		       to_char(s.departure_time, 'HH24:MI'), to_char(s.arrival_time, 'HH24:MI'),
+		       s.price_base, s.available_seats, t.has_wifi, t.has_food
+		FROM schedules s
+		JOIN trains t ON t.id = s.train_id
+		JOIN stations o ON o.id = s.origin_id
+		JOIN stations d ON d.id = s.destination_id
+		WHERE s.destination_id = $1 AND s.day_of_week = $2 AND s.is_active
+		  AND s.arrival_time <= $3
+		  AND s.available_seats >= $4
+		  AND (t.out_of_service_from IS NULL
+		       OR NOT ($5::date BETWEEN t.out_of_service_from AND t.out_of_service_until))`
+	args := []interface{}{dest.ID, int(travelDate.Weekday()), arrivalBefore, passengerCount, date}
+
+	if origin != "" {
+		orig, err := FindStationByNameOrCode(origin)
+		if err != nil {
+			return nil, err
+		}
+		query += fmt.Sprintf(" AND s.origin_id = $%d", len(args)+1)
+		args = append(args, orig.ID)
+	}
+	// Latest acceptable arrival first: the train closest to the deadline.
+	query += " ORDER BY s.arrival_time DESC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching trains by arrival: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.SearchResponse
+	for rows.Next() {
+		var r models.SearchResponse
+		if err := rows.Scan(
+			&r.ScheduleID, &r.TrainNumber, &r.TrainType, &r.Origin, &r.Destination,
+			&r.DepartureTime, &r.ArrivalTime, &r.PricePerAdult, &r.AvailableSeats,
+			&r.HasWifi, &r.HasFood,
+		); err != nil {
+			return nil, err
+		}
+		r.Duration = formatDuration(r.DepartureTime, r.ArrivalTime)
+		r.IsAvailable = r.AvailableSeats > 0
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := attachActiveAlerts(results, travelDate); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// FindNextAvailableDeparture returns the soonest departure between two
+// stations strictly after fromDateTime with at least one free seat, scanning
+// up to 7 days ahead. The result's Date field carries the travel date, which
+// may be later than fromDateTime's day.
+func FindNextAvailableDeparture(originCode, destinationCode string, fromDateTime time.Time) (*models.SearchResponse, error) {
+	origin, err := FindStationByNameOrCode(originCode)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := FindStationByNameOrCode(destinationCode)
+	if err != nil {
+		return nil, err
+	}
+
+	for offset := 0; offset < 7; offset++ {
+		day := fromDateTime.AddDate(0, 0, offset)
+		// Only the first day is cut off at fromDateTime's clock; later days
+		// start from midnight.
+		after := "00:00:00"
+		if offset == 0 {
+			after = fromDateTime.Format("15:04:05")
+		}
+
+		var r models.SearchResponse
+		err := db.QueryRow(`
+			SELECT s.id, t.number, t.type, o.name, d.name,
+			       to_char(s.departure_time, 'HH24:MI'), to_char(s.arrival_time, 'HH24:MI'),
+			       s.price_base, s.available_seats, t.has_wifi, t.has_food
+			FROM schedules s
+			JOIN trains t ON t.id = s.train_id
+			JOIN stations o ON o.id = s.origin_id
+			JOIN stations d ON d.id = s.destination_id
+			WHERE s.origin_id = $1 AND s.destination_id = $2
+			  AND s.day_of_week = $3 AND s.is_active
+			  AND s.available_seats >= 1
+			  AND s.departure_time > $4
+			  AND (t.out_of_service_from IS NULL
+			       OR NOT ($5::date BETWEEN t.out_of_service_from AND t.out_of_service_until))
+			ORDER BY s.departure_time
+			LIMIT 1`,
+			origin.ID, destination.ID, int(day.Weekday()), after, day.Format("2006-01-02"),
+		).Scan(
+			&r.ScheduleID, &r.TrainNumber, &r.TrainType, &r.Origin, &r.Destination,
+			&r.DepartureTime, &r.ArrivalTime, &r.PricePerAdult, &r.AvailableSeats,
+			&r.HasWifi, &r.HasFood,
+		)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("finding next departure: %w", err)
+		}
+
+		r.Duration = formatDuration(r.DepartureTime, r.ArrivalTime)
+		r.IsAvailable = true
+		r.Date = day.Format("2006-01-02")
+		results := []models.SearchResponse{r}
+		if err := attachActiveAlerts(results, day); err != nil {
+			return nil, err
+		}
+		return &results[0], nil
+	}
+	return nil, fmt.Errorf("no available departures from %s to %s in the next 7 days",
+		origin.Code, destination.Code)
+}
+
+// EstimateTravelTime averages the scheduled duration across all active
+// schedules between two stations, as a hint before any schedule lookup.
+func EstimateTravelTime(originCode, destinationCode string) (time.Duration, error) {
+	origin, err := FindStationByNameOrCode(originCode)
+	if err != nil {
+		return 0, err
+	}
+	destination, err := FindStationByNameOrCode(destinationCode)
+	if err != nil {
+		return 0, err
+	}
+
+	var seconds sql.NullFloat64
+	err = db.QueryRow(`
+		SELECT AVG(EXTRACT(EPOCH FROM (arrival_time - departure_time)))
+		FROM schedules
+		WHERE origin_id = $1 AND destination_id = $2 AND is_active`,
+		origin.ID, destination.ID,
+	).Scan(&seconds)
+	if err != nil {
+		return 0, fmt.Errorf("estimating travel time: %w", err)
+	}
+	if !seconds.Valid {
+		return 0, fmt.Errorf("no schedules between %s and %s", origin.Code, destination.Code)
+	}
+	return time.Duration(seconds.Float64) * time.Second, nil
+}
+
+// FormatTravelTime renders a duration in the same "3h 10m" style as the
+// per-result Duration field.
+func FormatTravelTime(d time.Duration) string {
+	return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// SearchAvailableSeatsSum returns the total available seats across the
+// active schedules matching a route and date. It is deliberately cheap so
+// the search ETag can be recomputed on every request: the sum changes the
+// moment a seat is booked or freed.
+func SearchAvailableSeatsSum(originName, destinationName, date string) (int, error) {
+	origin, err := FindStationByNameOrCode(originName)
+	if err != nil {
+		return 0, err
+	}
+	destination, err := FindStationByNameOrCode(destinationName)
+	if err != nil {
+		return 0, err
+	}
+	travelDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 0, fmt.Errorf("invalid date %q: use YYYY-MM-DD", date)
+	}
+
+	var sum int
+	err = db.QueryRow(
+		`SELECT COALESCE(SUM(available_seats), 0) FROM schedules
+		 WHERE origin_id = $1 AND destination_id = $2 AND day_of_week = $3 AND is_active`,
+		origin.ID, destination.ID, int(travelDate.Weekday()),
+	).Scan(&sum)
+	return sum, err
+}
+
+// GetScheduleCalendar returns the dates in a month on which a schedule
+// operates, based on its day_of_week. Dates with a cancelled exception are
+// left out.
+func GetScheduleCalendar(scheduleID int, month, year int) ([]string, error) {
+	var dayOfWeek int
+	err := db.QueryRow(
+		`SELECT day_of_week FROM schedules WHERE id = $1 AND is_active`, scheduleID,
+	).Scan(&dayOfWeek)
+	if err != nil {
+		return nil, fmt.Errorf("loading schedule %d: %w", scheduleID, err)
+	}
+	cancelled, err := cancelledExceptionDates(scheduleID, month, year)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []string
+	day := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	for day.Month() == time.Month(month) {
+		if int(day.Weekday()) == dayOfWeek && !cancelled[day.Format("2006-01-02")] {
+			dates = append(dates, day.Format("2006-01-02"))
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return dates, nil
+}
+
+// formatDuration renders the difference between two HH:MM strings as "3h 10m".
+func formatDuration(departure, arrival string) string {
+	dep, err1 := time.Parse("15:04", departure)
+	arr, err2 := time.Parse("15:04", arrival)
+	if err1 != nil || err2 != nil {
+		return ""
+	}
+	d := arr.Sub(dep)
+	if d < 0 {
+		d += 24 * time.Hour
+	}
+	return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SeatCheckRequest is one (schedule, date) pair to check.
+type SeatCheckRequest struct {
+	ScheduleID int    `json:"schedule_id" binding:"required"`
+	Date       string `json:"date" binding:"required"`
+}
+
+// SeatCheckResult carries the remaining seats for one pair.
+type SeatCheckResult struct {
+	ScheduleID     int    `json:"schedule_id"`
+	Date           string `json:"date"`
+	AvailableSeats int    `json:"available_seats"`
+	IsAvailable    bool   `json:"is_available"`
+}
+
+// CheckSeatAvailabilityBatch resolves many (schedule, date) pairs in a
+// single query, for the calendar view. Remaining seats are total_seats
+// minus confirmed passengers on that date. Unknown schedule ids are
+// reported with zero seats.
+func CheckSeatAvailabilityBatch(requests []SeatCheckRequest) ([]SeatCheckResult, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	values := make([]string, len(requests))
+	args := make([]interface{}, 0, len(requests)*2)
+	for i, r := range requests {
+		if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+			return nil, fmt.Errorf("invalid date %q: use YYYY-MM-DD", r.Date)
+		}
+		values[i] = fmt.Sprintf("($%d::int, $%d::date)", len(args)+1, len(args)+2)
+		args = append(args, r.ScheduleID, r.Date)
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT r.schedule_id, to_char(r.date, 'YYYY-MM-DD'),
+		       COALESCE(s.total_seats, 0) - COALESCE(booked.count, 0),
+		       s.id IS NOT NULL
+		FROM (VALUES %s) AS r(schedule_id, date)
+		LEFT JOIN schedules s ON s.id = r.schedule_id AND s.is_active
+		LEFT JOIN (
+			SELECT b.schedule_id, b.booking_date, COUNT(p.id) AS count
+			FROM bookings b
+			JOIN passengers p ON p.booking_id = b.id
+			WHERE b.status = 'confirmed'
+			GROUP BY b.schedule_id, b.booking_date
+		) booked ON booked.schedule_id = r.schedule_id AND booked.booking_date = r.date`,
+		strings.Join(values, ", ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("checking seat availability: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]SeatCheckResult, 0, len(requests))
+	for rows.Next() {
+		var r SeatCheckResult
+		var scheduleExists bool
+		if err := rows.Scan(&r.ScheduleID, &r.Date, &r.AvailableSeats, &scheduleExists); err != nil {
+			return nil, err
+		}
+		if !scheduleExists {
+			r.AvailableSeats = 0
+		}
+		r.IsAvailable = r.AvailableSeats > 0
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,21 @@
+// Package services contains the business logic of the train booking demo.
+// Handlers stay thin and delegate here; all SQL lives in this package.
+package services
+
+import (
+	"database/sql"
+
+	"trainbooking/config"
+)
+
+var (
+	db  *sql.DB
+	cfg *config.Config
+)
+
+// Init wires the shared database pool and configuration into the package.
+// It must be called once at startup before any other function.
+func Init(database *sql.DB, c *config.Config) {
+	db = database
+	cfg = c
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"trainbooking/models"
+)
+
+// SaveConversationMessage appends one turn to the conversation history.
+// functionCall may be nil for plain text turns.
+func SaveConversationMessage(sessionID, role, message string, functionCall *models.FunctionCall) error {
+	return saveConversationMessage(sessionID, role, message, functionCall, nil)
+}
+
+// saveConversationMessage is SaveConversationMessage plus the optional
+// client-supplied metadata stored with the turn.
+func saveConversationMessage(sessionID, role, message string, functionCall *models.FunctionCall, metadata map[string]string) error {
+	var callPayload, metadataPayload interface{}
+	if functionCall != nil {
+		b, err := json.Marshal(functionCall)
+		if err != nil {
+			return err
+		}
+		callPayload = string(b)
+	}
+	if len(metadata) > 0 {
+		b, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		metadataPayload = string(b)
+	}
+	_, err := db.Exec(
+		`INSERT INTO conversation_history (session_id, role, message, function_call, metadata)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		sessionID, role, message, callPayload, metadataPayload,
+	)
+	return err
+}
+
+// GetConversationHistory returns the most recent limit turns of a session in
+// chronological order.
+func GetConversationHistory(sessionID string, limit int) ([]models.ChatMessage, error) {
+	rows, err := db.Query(
+		`SELECT role, message FROM (
+		     SELECT role, message, timestamp FROM conversation_history
+		     WHERE session_id = $1 ORDER BY timestamp DESC LIMIT $2
+		 ) h ORDER BY h.timestamp`,
+		sessionID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.ChatMessage
+	for rows.Next() {
+		var m models.ChatMessage
+		if err := rows.Scan(&m.Role, &m.Content); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// GetLatestFunctionCallBySession returns the most recent function the AI
+// executed in a session, or nil when it never called one. Used to restore
+// context when a session resumes mid-booking.
+func GetLatestFunctionCallBySession(sessionID string) (*models.FunctionCall, error) {
+	call, _, err := latestFunctionCallWithTime(sessionID)
+	return call, err
+}
+
+// latestFunctionCallWithTime also reports when the call happened, so the
+// chat pipeline can decide whether it is still fresh enough to inject.
+func latestFunctionCallWithTime(sessionID string) (*models.FunctionCall, time.Time, error) {
+	var raw []byte
+	var at time.Time
+	err := db.QueryRow(
+		`SELECT function_call, timestamp FROM conversation_history
+		 WHERE session_id = $1 AND role = 'assistant' AND function_call IS NOT NULL
+		 ORDER BY timestamp DESC LIMIT 1`,
+		sessionID,
+	).Scan(&raw, &at)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var call models.FunctionCall
+	if err := json.Unmarshal(raw, &call); err != nil {
+		return nil, time.Time{}, fmt.Errorf("decoding function call: %w", err)
+	}
+	return &call, at, nil
+}
+
+// ConversationSearchResult is one hit of the support conversation search.
+type ConversationSearchResult struct {
+	SessionID      string    `json:"session_id"`
+	Role           string    `json:"role"`
+	MessagePreview string    `json:"message_preview"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// SearchConversations finds conversation messages containing a phrase
+// (booking reference, passenger name, ...) in a time range, newest first,
+// capped at 100 results for support tooling.
+func SearchConversations(q string, from, to time.Time) ([]ConversationSearchResult, error) {
+	rows, err := db.Query(
+		`SELECT session_id, role, LEFT(message, 160), timestamp
+		 FROM conversation_history
+		 WHERE message ILIKE '%' || $1 || '%' AND timestamp BETWEEN $2 AND $3
+		 ORDER BY timestamp DESC
+		 LIMIT 100`,
+		q, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []ConversationSearchResult
+	for rows.Next() {
+		var r ConversationSearchResult
+		if err := rows.Scan(&r.SessionID, &r.Role, &r.MessagePreview, &r.Timestamp); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// PurgeExpiredSessions deletes all conversation history older than the TTL
+// and returns the number of rows removed.
+func PurgeExpiredSessions(ttl time.Duration) (int64, error) {
+	res, err := db.Exec(
+		`DELETE FROM conversation_history WHERE timestamp < $1`,
+		time.Now().Add(-ttl),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// validBroadcastSeverities are the accepted severity labels for broadcast
+// messages, matching the ones used by schedule alerts.
+var validBroadcastSeverities = map[string]bool{"info": true, "warning": true, "critical": true}
+
+// BroadcastMessage pushes a service announcement into every active session
+// (one with a message in the last 24 hours) by appending an assistant turn
+// to each conversation. Users see it as part of the context on their next
+// message. Returns how many sessions received the announcement.
+func BroadcastMessage(message, severity string) (int, error) {
+	if message == "" {
+		return 0, fmt.Errorf("message must not be empty")
+	}
+	if !validBroadcastSeverities[severity] {
+		return 0, fmt.Errorf("invalid severity %q: must be info, warning or critical", severity)
+	}
+
+	res, err := db.Exec(
+		`INSERT INTO conversation_history (session_id, role, message)
+		 SELECT DISTINCT session_id, 'assistant', $1
+		 FROM conversation_history
+		 WHERE timestamp >= now() - interval '24 hours'`,
+		fmt.Sprintf("[%s] Service announcement: %s", strings.ToUpper(severity), message),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("broadcasting message: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// PurgeOrphanedConversations deletes abandoned sessions: conversations whose
+// last message is older than maxAge and that never produced a booking.
+// Unlike PurgeExpiredSessions it leaves old sessions with bookings intact.
+// It returns the number of sessions and messages removed.
+func PurgeOrphanedConversations(maxAge time.Duration) (sessionCount, messageCount int64, err error) {
+	rows, err := db.Query(
+		`WITH purged AS (
+		     DELETE FROM conversation_history
+		     WHERE session_id IN (
+		         SELECT session_id FROM conversation_history
+		         GROUP BY session_id
+		         HAVING MAX(timestamp) < $1
+		     )
+		     AND session_id NOT IN (
+		         SELECT session_id FROM bookings WHERE session_id IS NOT NULL
+		     )
+		     RETURNING session_id
+		 )
+		 SELECT COUNT(DISTINCT session_id), COUNT(*) FROM purged`,
+		time.Now().Add(-maxAge),
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("purging orphaned conversations: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&sessionCount, &messageCount); err != nil {
+			return 0, 0, err
+		}
+	}
+	return sessionCount, messageCount, rows.Err()
+}
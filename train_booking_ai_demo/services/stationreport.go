@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"time"
+)
+
+// DestCount is one destination row in a station report.
+type DestCount struct {
+	Destination string `json:"destination"`
+	Bookings    int    `json:"bookings"`
+}
+
+// StationReport is the operations snapshot of one station.
+type StationReport struct {
+	StationCode              string      `json:"station_code"`
+	StationName              string      `json:"station_name"`
+	City                     string      `json:"city"`
+	ReportDate               string      `json:"report_date"`
+	DeparturesToday          int         `json:"departures_today"`
+	ArrivalsToday            int         `json:"arrivals_today"`
+	TopDestinations          []DestCount `json:"top_destinations"`
+	TotalPassengersThisMonth int         `json:"total_passengers_this_month"`
+	RevenueThisMonth         float64     `json:"revenue_this_month"`
+	AvgOccupancyPct          float64     `json:"avg_occupancy_pct"`
+}
+
+// GenerateStationReport builds the operations snapshot for one station:
+// today's traffic, the most booked destinations of the last 30 days, and
+// this month's volume. Stations without bookings report zeros.
+func GenerateStationReport(stationCode string) (StationReport, error) {
+	report := StationReport{ReportDate: time.Now().Format("2006-01-02")}
+
+	station, err := GetStationByCode(stationCode)
+	if err != nil {
+		return report, err
+	}
+	report.StationCode = station.Code
+	report.StationName = station.Name
+	report.City = station.City
+
+	today := int(time.Now().Weekday())
+	err = db.QueryRow(`
+		SELECT COUNT(*) FILTER (WHERE origin_id = $1),
+		       COUNT(*) FILTER (WHERE destination_id = $1)
+		FROM schedules
+		WHERE is_active AND day_of_week = $2`,
+		station.ID, today,
+	).Scan(&report.DeparturesToday, &report.ArrivalsToday)
+	if err != nil {
+		return report, fmt.Errorf("counting today's traffic: %w", err)
+	}
+
+	rows, err := db.Query(`
+		SELECT d.name, COUNT(*)
+		FROM bookings b
+		JOIN schedules s ON s.id = b.schedule_id
+		JOIN stations d ON d.id = s.destination_id
+		WHERE s.origin_id = $1 AND b.status = 'confirmed'
+		  AND b.created_at > now() - interval '30 days'
+		GROUP BY d.name
+		ORDER BY COUNT(*) DESC
+		LIMIT 5`, station.ID)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d DestCount
+		if err := rows.Scan(&d.Destination, &d.Bookings); err != nil {
+			return report, err
+		}
+		report.TopDestinations = append(report.TopDestinations, d)
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	monthStart := time.Now().Format("2006-01") + "-01"
+	err = db.QueryRow(`
+		SELECT COALESCE(SUM(b.passenger_count), 0),
+		       COALESCE(SUM(b.total_price), 0),
+		       COALESCE(AVG(100.0 * b.passenger_count / s.total_seats), 0)
+		FROM bookings b
+		JOIN schedules s ON s.id = b.schedule_id
+		WHERE s.origin_id = $1 AND b.status = 'confirmed'
+		  AND b.booking_date >= $2::date`,
+		station.ID, monthStart,
+	).Scan(&report.TotalPassengersThisMonth, &report.RevenueThisMonth, &report.AvgOccupancyPct)
+	if err != nil {
+		return report, fmt.Errorf("summing this month's volume: %w", err)
+	}
+	report.AvgOccupancyPct = round2(report.AvgOccupancyPct)
+
+	return report, nil
+}
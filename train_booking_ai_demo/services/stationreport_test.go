@@ -0,0 +1,24 @@
+package services
+
+import "testing"
+
+func TestGenerateStationReportWithoutBookings(t *testing.T) {
+	setupTestDB(t)
+
+	// VE is seeded but has no seeded bookings; the report must come back
+	// with zeros, not an error or a panic.
+	report, err := GenerateStationReport("VE")
+	if err != nil {
+		t.Fatalf("GenerateStationReport: %v", err)
+	}
+	if report.StationCode != "VE" {
+		t.Errorf("station code = %q, want VE", report.StationCode)
+	}
+	if report.TotalPassengersThisMonth != 0 || report.RevenueThisMonth != 0 {
+		t.Errorf("expected zero volume, got %d passengers, %.2f revenue",
+			report.TotalPassengersThisMonth, report.RevenueThisMonth)
+	}
+	if len(report.TopDestinations) != 0 {
+		t.Errorf("expected no top destinations, got %v", report.TopDestinations)
+	}
+}
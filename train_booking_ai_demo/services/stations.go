@@ -0,0 +1,205 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"github.com/lib/pq"
+
+	"trainbooking/models"
+)
+
+// trgmUnavailable is set when a similarity() call fails because pg_trgm is
+// missing; from then on fuzzy matching degrades to ILIKE.
+var trgmUnavailable atomic.Bool
+
+// GetAllStations returns every station ordered by name.
+func GetAllStations() ([]models.Station, error) {
+	rows, err := db.Query(`SELECT id, code, name, city, COALESCE(latitude, 0), COALESCE(longitude, 0), COALESCE(timezone, 'Europe/Rome') FROM stations ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("querying stations: %w", err)
+	}
+	defer rows.Close()
+
+	var stations []models.Station
+	for rows.Next() {
+		var s models.Station
+		if err := rows.Scan(&s.ID, &s.Code, &s.Name, &s.City, &s.Latitude, &s.Longitude, &s.Timezone); err != nil {
+			return nil, err
+		}
+		stations = append(stations, s)
+	}
+	return stations, rows.Err()
+}
+
+// GetStationByCode looks up a station by its exact code.
+func GetStationByCode(code string) (*models.Station, error) {
+	var s models.Station
+	err := db.QueryRow(
+		`SELECT id, code, name, city, COALESCE(latitude, 0), COALESCE(longitude, 0), COALESCE(timezone, 'Europe/Rome') FROM stations WHERE code = $1`,
+		strings.ToUpper(code),
+	).Scan(&s.ID, &s.Code, &s.Name, &s.City, &s.Latitude, &s.Longitude, &s.Timezone)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("station %q not found", code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// FindStationByNameOrCode resolves user input (which may be a code, a city,
+// a station name or a typo of any of those) to a station. Exact code matches
+// win; otherwise the best pg_trgm similarity above 0.3 is used.
+func FindStationByNameOrCode(query string) (*models.Station, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("empty station query")
+	}
+
+	if s, err := GetStationByCode(query); err == nil {
+		return s, nil
+	}
+
+	if !trgmUnavailable.Load() {
+		s, err := findStationBySimilarity(query)
+		if isUndefinedFunction(err) {
+			trgmUnavailable.Store(true)
+			log.Printf("pg_trgm not available, falling back to ILIKE station matching: %v", err)
+		} else if err == nil || !isNoMatch(err) {
+			return s, err
+		}
+	} else if s, err := findStationByILike(query); err == nil || !isNoMatch(err) {
+		return s, err
+	}
+
+	// Last resort for short codes and swapped words, which trigram
+	// similarity handles poorly.
+	return findStationByLevenshtein(query)
+}
+
+// isNoMatch distinguishes "nothing matched" from real query failures.
+func isNoMatch(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "no station matching")
+}
+
+func findStationBySimilarity(query string) (*models.Station, error) {
+	var s models.Station
+	err := db.QueryRow(
+		`SELECT id, code, name, city, COALESCE(latitude, 0), COALESCE(longitude, 0),
+		        COALESCE(timezone, 'Europe/Rome')
+		 FROM stations
+		 WHERE similarity(name, $1) > 0.3 OR similarity(city, $1) > 0.3
+		 ORDER BY GREATEST(similarity(name, $1), similarity(city, $1)) DESC
+		 LIMIT 1`,
+		query,
+	).Scan(&s.ID, &s.Code, &s.Name, &s.City, &s.Latitude, &s.Longitude, &s.Timezone)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no station matching %q", query)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("searching station %q: %w", query, err)
+	}
+	return &s, nil
+}
+
+// findStationByILike is the degraded matcher used without pg_trgm: substring
+// matches only, no typo tolerance.
+func findStationByILike(query string) (*models.Station, error) {
+	var s models.Station
+	err := db.QueryRow(
+		`SELECT id, code, name, city, COALESCE(latitude, 0), COALESCE(longitude, 0),
+		        COALESCE(timezone, 'Europe/Rome')
+		 FROM stations
+		 WHERE name ILIKE '%' || $1 || '%' OR city ILIKE '%' || $1 || '%'
+		 ORDER BY name
+		 LIMIT 1`,
+		query,
+	).Scan(&s.ID, &s.Code, &s.Name, &s.City, &s.Latitude, &s.Longitude, &s.Timezone)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no station matching %q", query)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("searching station %q: %w", query, err)
+	}
+	return &s, nil
+}
+
+// maxLevenshteinDistance is how many edits away a query may be and still
+// match a station.
+const maxLevenshteinDistance = 2
+
+// findStationByLevenshtein compares the query against every station name
+// and code and returns the closest one within maxLevenshteinDistance. Ties
+// go to the station with the shorter name.
+func findStationByLevenshtein(query string) (*models.Station, error) {
+	stations, err := GetAllStations()
+	if err != nil {
+		return nil, err
+	}
+
+	lowered := strings.ToLower(query)
+	var best *models.Station
+	bestDistance := maxLevenshteinDistance + 1
+	for i, s := range stations {
+		distance := levenshteinDistance(lowered, strings.ToLower(s.Name))
+		if d := levenshteinDistance(lowered, strings.ToLower(s.Code)); d < distance {
+			distance = d
+		}
+		if distance < bestDistance ||
+			(distance == bestDistance && best != nil && len(s.Name) < len(best.Name)) {
+			best = &stations[i]
+			bestDistance = distance
+		}
+	}
+	if best == nil || bestDistance > maxLevenshteinDistance {
+		return nil, fmt.Errorf("no station matching %q", query)
+	}
+	log.Printf("station query %q matched %s (%s) by levenshtein distance %d",
+		query, best.Name, best.Code, bestDistance)
+	return best, nil
+}
+
+// levenshteinDistance is the classic two-row dynamic programming edit
+// distance, adequate for the handful of stations in the fleet.
+func levenshteinDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// isUndefinedFunction reports whether err is PostgreSQL's "function does not
+// exist" error, raised for similarity() when pg_trgm is missing.
+func isUndefinedFunction(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "42883"
+}
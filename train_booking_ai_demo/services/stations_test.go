@@ -0,0 +1,22 @@
+package services
+
+import "testing"
+
+func TestLevenshteinDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"milano", "milano", 0},
+		{"milano", "milno", 1},
+		{"roma", "rmoa", 2},
+		{"mi", "rm", 2},
+		{"", "roma", 4},
+		{"firenze", "venezia", 6},
+	}
+	for _, tc := range cases {
+		if got := levenshteinDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("levenshteinDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
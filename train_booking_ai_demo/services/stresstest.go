@@ -0,0 +1,55 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+
+	"trainbooking/models"
+)
+
+// SimulateBookingLoad fires n concurrent single-adult bookings at one
+// schedule and date to exercise the seat locking. It returns the references
+// of the bookings that succeeded and verifies the seat counter never went
+// negative; use it only against disposable data.
+func SimulateBookingLoad(scheduleID int, date string, n int) ([]string, error) {
+	if n < 1 || n > 500 {
+		return nil, fmt.Errorf("n must be between 1 and 500")
+	}
+
+	var mu sync.Mutex
+	var refs []string
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			booking, err := CreateBooking(models.BookingRequest{
+				ScheduleID: scheduleID,
+				Date:       date,
+				Passengers: []models.PassengerCreateRequest{
+					{Name: fmt.Sprintf("Load Test %d", i), PassengerType: "adult"},
+				},
+			})
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			refs = append(refs, booking.BookingRef)
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	var availableSeats int
+	err := db.QueryRow(
+		`SELECT available_seats FROM schedules WHERE id = $1`, scheduleID,
+	).Scan(&availableSeats)
+	if err != nil {
+		return refs, err
+	}
+	if availableSeats < 0 {
+		return refs, fmt.Errorf("oversold: available_seats is %d after %d bookings",
+			availableSeats, len(refs))
+	}
+	return refs, nil
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"trainbooking/models"
+)
+
+// summaryInstruction asks the model for a compact recap instead of a reply.
+const summaryInstruction = `Summarise the following conversation between a user and a train booking assistant in exactly 3 sentences. Keep routes, dates, passenger details and booking references. Do not add greetings or commentary; output only the summary, in the user's language.`
+
+// GetConversationSummary asks the configured AI provider for a 3-sentence
+// summary of a session's full history and stores it in session_summaries.
+// The stored summary is injected into the system prompt of later turns, so
+// the assistant keeps context beyond the history window.
+func GetConversationSummary(sessionID string) (string, error) {
+	// Full history, not the historyLimit window: the point is to capture
+	// what the window is about to drop.
+	history, err := GetConversationHistory(sessionID, 1000)
+	if err != nil {
+		return "", fmt.Errorf("loading history: %w", err)
+	}
+	if len(history) == 0 {
+		return "", fmt.Errorf("session %s has no messages", sessionID)
+	}
+
+	messages := make([]models.ChatMessage, 0, len(history)+1)
+	messages = append(messages, models.ChatMessage{Role: "system", Content: summaryInstruction})
+	messages = append(messages, history...)
+
+	summary, err := completeText(context.Background(), messages)
+	if err != nil {
+		return "", fmt.Errorf("summarising session: %w", err)
+	}
+
+	_, err = db.Exec(
+		`INSERT INTO session_summaries (session_id, summary, message_count_at_summary_time)
+		 VALUES ($1, $2, $3)`,
+		sessionID, summary, len(history),
+	)
+	if err != nil {
+		return "", fmt.Errorf("storing summary: %w", err)
+	}
+	return summary, nil
+}
+
+// maybeSummariseSession regenerates a session's summary when the history has
+// grown by at least a full window since the previous summary. It runs in the
+// background after a chat turn, so errors are only logged.
+func maybeSummariseSession(sessionID string, summarisedCount int) {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM conversation_history WHERE session_id = $1`, sessionID,
+	).Scan(&count)
+	if err != nil {
+		log.Printf("counting session %s messages: %v", sessionID, err)
+		return
+	}
+	if count-summarisedCount < historyLimit {
+		return
+	}
+	if _, err := GetConversationSummary(sessionID); err != nil {
+		log.Printf("summarising session %s: %v", sessionID, err)
+	}
+}
+
+// latestSessionSummary returns the newest stored summary for a session and
+// the message count it covered, or ("", 0, nil) when none exists yet.
+func latestSessionSummary(sessionID string) (string, int, error) {
+	var summary string
+	var count int
+	err := db.QueryRow(
+		`SELECT summary, message_count_at_summary_time FROM session_summaries
+		 WHERE session_id = $1 ORDER BY created_at DESC LIMIT 1`,
+		sessionID,
+	).Scan(&summary, &count)
+	if err == sql.ErrNoRows {
+		return "", 0, nil
+	}
+	return summary, count, err
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+
+	"trainbooking/config"
+)
+
+// setupTestDB connects the package to the database named by
+// TEST_DATABASE_URL and skips the test when the variable is unset, so
+// integration tests only run where a disposable database is available.
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set; skipping integration test")
+	}
+
+	testDB, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	if err := testDB.Ping(); err != nil {
+		t.Fatalf("pinging test database: %v", err)
+	}
+
+	Init(testDB, config.Load())
+	t.Cleanup(func() {
+		// Sweep anything a test created under the shared TEST- prefix
+		// before handing the database to the next test.
+		if _, err := PurgeTestData("TEST-"); err != nil {
+			t.Logf("purging test data: %v", err)
+		}
+		testDB.Close()
+	})
+	return testDB
+}
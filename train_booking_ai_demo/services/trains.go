@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"trainbooking/models"
+)
+
+// TrainFilter narrows ListTrains; nil pointer fields mean "any".
+type TrainFilter struct {
+	Type    string
+	HasWifi *bool
+	HasFood *bool
+}
+
+// ListTrains returns the fleet, optionally filtered, each train annotated
+// with its number of active schedules.
+func ListTrains(filter TrainFilter) ([]models.Train, error) {
+	query := `
+		SELECT t.id, t.number, t.type, t.total_seats, t.has_wifi, t.has_food,
+		       t.out_of_service_from, t.out_of_service_until,
+		       (SELECT COUNT(*) FROM schedules s WHERE s.train_id = t.id AND s.is_active)
+		FROM trains t
+		WHERE TRUE`
+	var args []interface{}
+
+	if filter.Type != "" {
+		query += fmt.Sprintf(" AND t.type = $%d", len(args)+1)
+		args = append(args, filter.Type)
+	}
+	if filter.HasWifi != nil {
+		query += fmt.Sprintf(" AND t.has_wifi = $%d", len(args)+1)
+		args = append(args, *filter.HasWifi)
+	}
+	if filter.HasFood != nil {
+		query += fmt.Sprintf(" AND t.has_food = $%d", len(args)+1)
+		args = append(args, *filter.HasFood)
+	}
+	query += " ORDER BY t.number"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing trains: %w", err)
+	}
+	defer rows.Close()
+
+	var trains []models.Train
+	for rows.Next() {
+		var t models.Train
+		if err := rows.Scan(
+			&t.ID, &t.Number, &t.Type, &t.TotalSeats, &t.HasWifi, &t.HasFood,
+			&t.OutOfServiceFrom, &t.OutOfServiceUntil, &t.ActiveSchedulesCount,
+		); err != nil {
+			return nil, err
+		}
+		trains = append(trains, t)
+	}
+	return trains, rows.Err()
+}
+
+// SetTrainOutOfService marks a train as unavailable between two instants and
+// cancels (with full refund) every confirmed booking on its schedules in
+// that window. Returns the number of bookings cancelled.
+func SetTrainOutOfService(trainID int, from, until time.Time, reason string) (int, error) {
+	if !until.After(from) {
+		return 0, fmt.Errorf("until must be after from")
+	}
+
+	res, err := db.Exec(
+		`UPDATE trains SET out_of_service_from = $1, out_of_service_until = $2 WHERE id = $3`,
+		from, until, trainID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("updating train %d: %w", trainID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, fmt.Errorf("train %d not found", trainID)
+	}
+
+	rows, err := db.Query(`SELECT id FROM schedules WHERE train_id = $1`, trainID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var scheduleIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return 0, err
+		}
+		scheduleIDs = append(scheduleIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for _, scheduleID := range scheduleIDs {
+		n, err := BulkCancelBookings(scheduleID, from.Format("2006-01-02"), until.Format("2006-01-02"), reason)
+		if err != nil {
+			return cancelled, err
+		}
+		cancelled += n
+	}
+	return cancelled, nil
+}
+
+// ClearTrainOutOfService removes the out-of-service window from a train.
+func ClearTrainOutOfService(trainID int) error {
+	res, err := db.Exec(
+		`UPDATE trains SET out_of_service_from = NULL, out_of_service_until = NULL WHERE id = $1`,
+		trainID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating train %d: %w", trainID, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("train %d not found", trainID)
+	}
+	return nil
+}
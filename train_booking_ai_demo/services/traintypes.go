@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"trainbooking/models"
+)
+
+// trainTypes is the single source of truth for the fleet's train types. The
+// per-km prices come from perKmRates so the /api/train-types endpoints, the
+// schedule price validation and the assistant's system prompt cannot drift
+// apart.
+var trainTypes = []models.TrainTypeInfo{
+	{
+		Code:             "FR",
+		Name:             "Frecciarossa",
+		Description:      "high speed",
+		MaxSpeedKmh:      300,
+		PricePerKm:       perKmRates["FR"],
+		HasWifi:          true,
+		HasFood:          true,
+		TypicalAmenities: []string{"wifi", "restaurant", "power sockets", "business class"},
+	},
+	{
+		Code:             "IC",
+		Name:             "Intercity",
+		Description:      "long distance",
+		MaxSpeedKmh:      200,
+		PricePerKm:       perKmRates["IC"],
+		HasWifi:          false,
+		HasFood:          true,
+		TypicalAmenities: []string{"mini-bar", "power sockets"},
+	},
+	{
+		Code:             "RG",
+		Name:             "Regionale",
+		Description:      "local stops, cheapest option",
+		MaxSpeedKmh:      160,
+		PricePerKm:       perKmRates["RG"],
+		HasWifi:          false,
+		HasFood:          false,
+		TypicalAmenities: []string{"bike spaces"},
+	},
+}
+
+// GetTrainTypes returns descriptive information about every train type.
+func GetTrainTypes() []models.TrainTypeInfo {
+	return trainTypes
+}
+
+// GetTrainType looks up one train type by its code (case-insensitive).
+func GetTrainType(code string) (models.TrainTypeInfo, error) {
+	for _, t := range trainTypes {
+		if strings.EqualFold(t.Code, code) {
+			return t, nil
+		}
+	}
+	return models.TrainTypeInfo{}, fmt.Errorf("unknown train type %q", code)
+}
+
+// trainTypePromptLines renders the train types as the bullet list used in
+// the assistant's system prompt.
+func trainTypePromptLines() string {
+	var b strings.Builder
+	for _, t := range trainTypes {
+		b.WriteString(fmt.Sprintf("- %s (%s): %s, up to %d km/h", t.Code, t.Name, t.Description, t.MaxSpeedKmh))
+		switch {
+		case t.HasWifi && t.HasFood:
+			b.WriteString(", wifi and restaurant on board")
+		case t.HasWifi:
+			b.WriteString(", wifi on board")
+		case t.HasFood:
+			b.WriteString(", food on board")
+		}
+		b.WriteString(fmt.Sprintf(", about EUR %.2f/km.\n", t.PricePerKm))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,89 @@
+package services
+
+import "fmt"
+
+// TravelRecord is one past (or upcoming) journey of a passenger, flattened
+// for the support UI.
+type TravelRecord struct {
+	BookingRef    string  `json:"booking_ref"`
+	Status        string  `json:"status"`
+	TrainNumber   string  `json:"train_number"`
+	Origin        string  `json:"origin"`
+	Destination   string  `json:"destination"`
+	BookingDate   string  `json:"booking_date"`
+	DepartureTime string  `json:"departure_time"`
+	Price         float64 `json:"price"`
+}
+
+// GetPassengerTravelHistory lists every booking a passenger appears on,
+// matched case-insensitively by exact name, newest travel date first.
+func GetPassengerTravelHistory(name string, page, pageSize int) ([]TravelRecord, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	rows, err := db.Query(
+		`SELECT b.booking_ref, b.status, t.number, o.name, d.name,
+		        to_char(b.booking_date, 'YYYY-MM-DD'), to_char(s.departure_time, 'HH24:MI'), p.price
+		 FROM passengers p
+		 JOIN bookings b ON b.id = p.booking_id
+		 JOIN schedules s ON s.id = b.schedule_id
+		 JOIN trains t ON t.id = s.train_id
+		 JOIN stations o ON o.id = s.origin_id
+		 JOIN stations d ON d.id = s.destination_id
+		 WHERE LOWER(p.name) = LOWER($1)
+		 ORDER BY b.booking_date DESC
+		 LIMIT $2 OFFSET $3`,
+		name, pageSize, (page-1)*pageSize,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("loading travel history: %w", err)
+	}
+	defer rows.Close()
+
+	var records []TravelRecord
+	for rows.Next() {
+		var r TravelRecord
+		err := rows.Scan(&r.BookingRef, &r.Status, &r.TrainNumber, &r.Origin, &r.Destination,
+			&r.BookingDate, &r.DepartureTime, &r.Price)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// FindSimilarPassengerNames returns distinct stored spellings close to the
+// given name (other than exact case-insensitive matches), so support agents
+// notice typos like "Marco Rosi" next to "Marco Rossi".
+func FindSimilarPassengerNames(name string) ([]string, error) {
+	rows, err := db.Query(
+		`SELECT DISTINCT p.name FROM passengers p
+		 WHERE LOWER(p.name) <> LOWER($1) AND similarity(p.name, $1) > 0.6
+		 ORDER BY p.name
+		 LIMIT 10`,
+		name,
+	)
+	if err != nil {
+		// similarity() needs pg_trgm; degrade to no suggestions without it.
+		if isUndefinedFunction(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			return nil, err
+		}
+		names = append(names, n)
+	}
+	return names, rows.Err()
+}
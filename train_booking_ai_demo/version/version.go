@@ -0,0 +1,13 @@
+// Package version holds build metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X trainbooking/version.Version=1.2.3"
+//
+// The Makefile build target fills these from git; plain go build leaves the
+// development defaults.
+package version
+
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)